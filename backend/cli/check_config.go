@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"golang-web-core/srv"
+	"strings"
+)
+
+// checkConfig loads the config and sets up the server without listening, so it fails for the same configs the
+// server would refuse to start with
+func checkConfig(args []string) error {
+	fs := newFlagSet("check-config", "[flags]")
+	flags := registerConfigFlags(fs).registerServerFlags(fs)
+	err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	config, files, err := flags.load()
+	if err != nil {
+		return err
+	}
+
+	server, err := srv.InspectServer(config)
+	if err != nil {
+		return err
+	}
+
+	srv.PrintServerConfig(server)
+	fmt.Printf("config is valid, read from %v\n", strings.Join(files, ", "))
+	return nil
+}
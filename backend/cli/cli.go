@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{name: "serve", summary: "start the server (default)", run: serve},
+	{name: "check-config", summary: "load and verify the config, then print it", run: checkConfig},
+	{name: "routes", summary: "list the registered routes", run: routes},
+	{name: "version", summary: "print the version", run: version},
+	{name: "install-service", summary: "install a systemd user service that is started when the socket is used", run: installService},
+	{name: "ls", summary: "list a folder", run: ls},
+	{name: "cp", summary: "copy files and folders", run: cp},
+	{name: "mv", summary: "move files and folders", run: mv},
+	{name: "trash", summary: "move files and folders to the trash, list or restore it", run: trash},
+	{name: "tag", summary: "show, add or remove the tags of files and folders", run: tag},
+	{name: "search", summary: "search files by name or tag", run: search},
+	{name: "du", summary: "show the size of a folder and its largest files", run: du},
+}
+
+// usageError is returned for invalid arguments. the flag set has already printed the problem and the usage
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+// Run executes the command named by the first argument and returns the exit code. without a command, or when
+// the first argument is a flag, the server is started
+func Run(args []string) int {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	} else if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		name = "help"
+	}
+
+	if name == "help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+
+		err := c.run(args)
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if errors.As(err, &usageError{}) {
+			return 2
+		}
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	return 2
+}
+
+func printUsage(w *os.File) {
+	fmt.Fprintf(w, "usage: %v <command> [flags]\n\ncommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-17v%v\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nrun %v <command> -h to see the flags of a command\n", os.Args[0])
+}
+
+// newFlagSet creates the flag set of a command. synopsis describes its arguments, e.g. "[flags] <path>"
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %v %v %v\n", os.Args[0], name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags of commands that don't take arguments
+func parse(fs *flag.FlagSet, args []string) error {
+	_, err := parseArgs(fs, args, 0, 0)
+	return err
+}
+
+// parseArgs parses the flags and returns the arguments, which may come before, after or between the flags.
+// max < 0 allows any number of arguments
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	positional := []string{}
+	for {
+		err := fs.Parse(args)
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, usageError{err}
+		}
+
+		if fs.NArg() == 0 {
+			break
+		}
+		// everything after -- is an argument, even if it looks like a flag
+		if consumed := len(args) - fs.NArg(); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, fs.Args()...)
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	var err error
+	switch {
+	case len(positional) < min:
+		err = fmt.Errorf("expected at least %v arguments, got %v", min, len(positional))
+	case max >= 0 && len(positional) > max:
+		err = fmt.Errorf("unexpected argument %q", positional[max])
+	}
+	if err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		fs.Usage()
+		return nil, usageError{err}
+	}
+
+	return positional, nil
+}
@@ -0,0 +1,346 @@
+package cli
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Helper function to point every user directory into home, which is also the working directory and the allowed
+// root
+func setupHome(t *testing.T, home string) {
+	t.Helper()
+
+	config, err := filepath.Abs(filepath.Join("..", "configs", "default.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("XDG_RUNTIME_DIR", home)
+	t.Setenv("LFE_CONFIG", config)
+	t.Setenv("LFE_ALLOWED_ROOTS", home)
+	t.Chdir(home)
+}
+
+// Helper function to run the command line in home, see setupHome. it returns the exit code and what was printed
+// to stdout
+func runCLI(t *testing.T, home string, args ...string) (int, string) {
+	t.Helper()
+
+	setupHome(t, home)
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+
+	original := os.Stdout
+	os.Stdout = stdout
+	code := Run(args)
+	os.Stdout = original
+
+	out, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(out)
+}
+
+// Helper function to create files, folders end with a slash
+func createFiles(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/") {
+			err := os.MkdirAll(filepath.Join(dir, path), 0o755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		path = filepath.Join(dir, path)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, []byte("content of "+filepath.Base(path)), 0o644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    []string
+		args     []string
+		wantCode int
+		wantOut  []string
+		check    func(t *testing.T, home string)
+	}{
+		{
+			name:     "Version",
+			args:     []string{"version"},
+			wantCode: 0,
+			wantOut:  []string{"linux-file-explorer"},
+		},
+		{
+			name:     "Help",
+			args:     []string{"-h"},
+			wantCode: 0,
+		},
+		{
+			name:     "Unknown command",
+			args:     []string{"frobnicate"},
+			wantCode: 2,
+		},
+		{
+			name:     "Unknown flag",
+			args:     []string{"version", "-verbose"},
+			wantCode: 2,
+		},
+		{
+			name:     "Check config with overrides",
+			args:     []string{"check-config", "-port", "4123", "-host", "127.0.0.2"},
+			wantCode: 0,
+			wantOut:  []string{"config is valid", "4123", "127.0.0.2"},
+		},
+		{
+			name:     "Check config with an invalid flag value",
+			args:     []string{"check-config", "-port", "http"},
+			wantCode: 2,
+		},
+		{
+			name:     "Check config with an invalid setting",
+			args:     []string{"check-config", "-env", "staging"},
+			wantCode: 1,
+		},
+		{
+			name:     "Routes",
+			args:     []string{"routes"},
+			wantCode: 0,
+			wantOut:  []string{"/healthz", "public"},
+		},
+		{
+			name:     "List",
+			files:    []string{"notes.txt", "photos/"},
+			args:     []string{"ls", "-json"},
+			wantCode: 0,
+			wantOut:  []string{`"name": "notes.txt"`, `"name": "photos"`},
+		},
+		{
+			name:     "List outside the allowed roots",
+			args:     []string{"ls", "/"},
+			wantCode: 1,
+		},
+		{
+			name:     "List with too many arguments",
+			args:     []string{"ls", "a", "b"},
+			wantCode: 2,
+		},
+		{
+			name:     "Copy",
+			files:    []string{"notes.txt", "backup/"},
+			args:     []string{"cp", "notes.txt", "backup"},
+			wantCode: 0,
+			wantOut:  []string{"notes.txt"},
+			check: func(t *testing.T, home string) {
+				_, err := os.Stat(filepath.Join(home, "backup", "notes.txt"))
+				if err != nil {
+					t.Errorf("Expected the file to be copied, but got: %v", err)
+				}
+			},
+		},
+		{
+			name:     "Copy without a destination",
+			files:    []string{"notes.txt"},
+			args:     []string{"cp", "notes.txt"},
+			wantCode: 2,
+		},
+		{
+			name:     "Move with flags after the arguments",
+			files:    []string{"notes.txt", "backup/notes.txt"},
+			args:     []string{"mv", "notes.txt", "backup", "-overwrite"},
+			wantCode: 0,
+			check: func(t *testing.T, home string) {
+				_, err := os.Stat(filepath.Join(home, "notes.txt"))
+				if !os.IsNotExist(err) {
+					t.Errorf("Expected the source to be gone, but got: %v", err)
+				}
+			},
+		},
+		{
+			name:     "Copy carries on after a failed source",
+			files:    []string{"a.txt", "c.txt", "backup/"},
+			args:     []string{"cp", "a.txt", "missing.txt", "c.txt", "backup"},
+			wantCode: 1,
+			wantOut:  []string{"missing.txt", "no such file or directory"},
+			check: func(t *testing.T, home string) {
+				for _, name := range []string{"a.txt", "c.txt"} {
+					_, err := os.Stat(filepath.Join(home, "backup", name))
+					if err != nil {
+						t.Errorf("Expected %v to be copied, but got: %v", name, err)
+					}
+				}
+			},
+		},
+		{
+			name:     "Trash",
+			files:    []string{"a.txt", "b.txt"},
+			args:     []string{"trash", "-json", "a.txt", "b.txt"},
+			wantCode: 0,
+			wantOut:  []string{`"name": "a.txt"`, `"name": "b.txt"`},
+			check: func(t *testing.T, home string) {
+				_, err := os.Stat(filepath.Join(home, ".local", "share", "Trash", "info", "a.txt.trashinfo"))
+				if err != nil {
+					t.Errorf("Expected a.txt to be trashed, but got: %v", err)
+				}
+			},
+		},
+		{
+			name:     "Trash carries on after a failed path",
+			files:    []string{"a.txt"},
+			args:     []string{"trash", "missing.txt", "a.txt"},
+			wantCode: 1,
+			wantOut:  []string{"missing.txt", "a.txt"},
+			check: func(t *testing.T, home string) {
+				_, err := os.Stat(filepath.Join(home, "a.txt"))
+				if !os.IsNotExist(err) {
+					t.Errorf("Expected a.txt to be trashed, but got: %v", err)
+				}
+			},
+		},
+		{
+			name:     "Restore an unknown item",
+			args:     []string{"trash", "-restore", "missing.txt"},
+			wantCode: 1,
+			wantOut:  []string{"missing.txt"},
+		},
+		{
+			name:     "Trash without paths",
+			args:     []string{"trash"},
+			wantCode: 2,
+		},
+		{
+			name:     "Disk usage",
+			files:    []string{"photos/a.jpg", "photos/b.jpg"},
+			args:     []string{"du", "-json", "photos"},
+			wantCode: 0,
+			wantOut:  []string{`"files": 2`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			home := t.TempDir()
+			createFiles(t, home, tc.files...)
+
+			code, out := runCLI(t, home, tc.args...)
+			if code != tc.wantCode {
+				t.Fatalf("Expected exit code %v, but got %v: %v", tc.wantCode, code, out)
+			}
+			for _, want := range tc.wantOut {
+				if !strings.Contains(out, want) {
+					t.Errorf("Expected the output to contain %q, but got: %v", want, out)
+				}
+			}
+			if tc.check != nil {
+				tc.check(t, home)
+			}
+		})
+	}
+}
+
+// Helper function to find a port nothing is listening on
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+func TestServe(t *testing.T) {
+	home := t.TempDir()
+	setupHome(t, home)
+	discovery := filepath.Join(home, "linux-file-explorer", "server.json")
+
+	// the server prints its config, keep it out of the test output
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	original := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = original }()
+
+	port := freePort(t)
+	exited := make(chan int)
+	go func() {
+		exited <- Run([]string{"serve", "-host", "127.0.0.1", "-port", port})
+	}()
+
+	// the discovery file is published once the server is listening
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		_, err := os.Stat(discovery)
+		if err == nil {
+			break
+		}
+		select {
+		case code := <-exited:
+			t.Fatalf("Expected the server to keep running, but it exited with %v", code)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the discovery file to be published, but got: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	content, err := os.ReadFile(discovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"port": `+port) {
+		t.Errorf("Expected the discovery file to name the listener, but got: %s", content)
+	}
+
+	code := Run([]string{"serve", "-host", "127.0.0.1", "-port", freePort(t)})
+	if code != 1 {
+		t.Errorf("Expected a second instance to be refused with exit code 1, but got %v", code)
+	}
+
+	err = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case code := <-exited:
+		if code != 0 {
+			t.Errorf("Expected the server to shut down cleanly, but it exited with %v", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Expected the server to shut down")
+	}
+
+	_, err = os.Stat(discovery)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the discovery file to be removed on shutdown, but got: %v", err)
+	}
+}
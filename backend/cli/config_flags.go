@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"flag"
+	"golang-web-core/repositories"
+	"golang-web-core/srv/cfg"
+	"strconv"
+	"strings"
+)
+
+// stringsFlag collects a flag that can be given more than once
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// configFlags are the flags of every command that loads the config
+type configFlags struct {
+	configs  stringsFlag
+	port     int
+	hosts    stringsFlag
+	listen   string
+	logLevel string
+	env      string
+}
+
+func registerConfigFlags(fs *flag.FlagSet) *configFlags {
+	flags := &configFlags{}
+	fs.Var(&flags.configs, "config", "config file or name in ./configs, can be repeated to layer files (default: $"+cfg.ConfigEnv+" or the first of "+strings.Join(cfg.SearchPaths(), ", ")+")")
+	return flags
+}
+
+// registerServerFlags adds the flags that override server settings
+func (f *configFlags) registerServerFlags(fs *flag.FlagSet) *configFlags {
+	fs.IntVar(&f.port, "port", 0, "tcp port, overrides port")
+	fs.Var(&f.hosts, "host", "address to bind the tcp port to, can be repeated, overrides hosts")
+	fs.StringVar(&f.listen, "listen", "", "unix socket path to listen on, overrides unixSocket")
+	fs.StringVar(&f.logLevel, "log-level", "", "debug, info, warn or error, overrides logging.level")
+	fs.StringVar(&f.env, "env", "", "development or production, overrides env")
+	return f
+}
+
+// overrides returns the settings given as flags. they win over the config files and LFE_* variables
+func (f *configFlags) overrides() cfg.Overrides {
+	overrides := cfg.Overrides{}
+
+	if f.port != 0 {
+		overrides["port"] = strconv.Itoa(f.port)
+	}
+	if len(f.hosts) > 0 {
+		overrides["hosts"] = strings.Join(f.hosts, ",")
+	}
+	if f.listen != "" {
+		overrides["unixSocket.enabled"] = "true"
+		overrides["unixSocket.path"] = f.listen
+	}
+	if f.logLevel != "" {
+		overrides["logging.level"] = f.logLevel
+	}
+	if f.env != "" {
+		overrides["env"] = f.env
+	}
+
+	return overrides
+}
+
+// load reads the config and checks its repositories, which cfg leaves to the repository registries
+func (f *configFlags) load() (cfg.Config, []string, error) {
+	config, files, err := f.overrides().Load(f.configs...)
+	if err != nil {
+		return cfg.Config{}, nil, err
+	}
+
+	err = repositories.Verify(config)
+	if err != nil {
+		return cfg.Config{}, nil, err
+	}
+	return config, files, nil
+}
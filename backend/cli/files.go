@@ -0,0 +1,398 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"golang-web-core/services/files"
+	"golang-web-core/util/jobs"
+	"golang-web-core/util/sandbox"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// fileFlags are the flags of the commands that work on files
+type fileFlags struct {
+	*configFlags
+	json bool
+}
+
+func registerFileFlags(fs *flag.FlagSet) *fileFlags {
+	flags := &fileFlags{configFlags: registerConfigFlags(fs)}
+	fs.BoolVar(&flags.json, "json", false, "print json instead of a table")
+	return flags
+}
+
+// service sets up the file service the same way the server does, so the allowed roots and denied paths of the
+// config apply. the returned context is cancelled on SIGINT or SIGTERM, which stops a running copy
+func (f *fileFlags) service() (context.Context, context.CancelFunc, *files.Service, error) {
+	config, _, err := f.load()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	sb, err := sandbox.New(config.AllowedRoots, config.DeniedPaths)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	return ctx, cancel, files.New(sb, jobs.NewTracker(), ""), nil
+}
+
+// print writes v as json, or the rows as a table
+func (f *fileFlags) print(v any, header []string, rows [][]string) error {
+	if f.json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// absPaths makes the arguments absolute so they can be given relative to the working directory
+func absPaths(paths []string) ([]string, error) {
+	abs := make([]string, len(paths))
+	for i, path := range paths {
+		var err error
+		abs[i], err = filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return abs, nil
+}
+
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%vB", bytes)
+	}
+
+	size, exp := float64(bytes), 0
+	for size >= unit*unit && exp < 5 {
+		size /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", size/unit, "KMGTPE"[exp])
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func entryRows(entries []files.Entry) [][]string {
+	rows := [][]string{}
+	for _, entry := range entries {
+		rows = append(rows, []string{entry.Mode, formatSize(entry.Size), formatTime(entry.ModifiedAt), entry.Path})
+	}
+	return rows
+}
+
+var entryHeader = []string{"MODE", "SIZE", "MODIFIED", "PATH"}
+
+func ls(args []string) error {
+	fs := newFlagSet("ls", "[flags] [path]")
+	flags := registerFileFlags(fs)
+	all := fs.Bool("all", false, "include hidden files")
+	args, err := parseArgs(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	paths, err := absPaths(args)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, service, err := flags.service()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	entries, err := service.List(ctx, paths[0], *all)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	for _, entry := range entries {
+		name := entry.Name
+		if entry.IsDirectory {
+			name += "/"
+		}
+		rows = append(rows, []string{entry.Mode, formatSize(entry.Size), formatTime(entry.ModifiedAt), name})
+	}
+	return flags.print(entries, []string{"MODE", "SIZE", "MODIFIED", "NAME"}, rows)
+}
+
+func cp(args []string) error {
+	return transfer("cp", args, (*files.Service).Copy)
+}
+
+func mv(args []string) error {
+	return transfer("mv", args, (*files.Service).Move)
+}
+
+type transferFunc func(s *files.Service, ctx context.Context, source, destination string, overwrite bool) (files.Transfer, error)
+
+// transfer copies or moves every source to the destination, which has to be a folder for more than one source
+func transfer(name string, args []string, fn transferFunc) error {
+	fs := newFlagSet(name, "[flags] <source>... <destination>")
+	flags := registerFileFlags(fs)
+	overwrite := fs.Bool("overwrite", false, "replace existing files")
+	args, err := parseArgs(fs, args, 2, -1)
+	if err != nil {
+		return err
+	}
+
+	paths, err := absPaths(args)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, service, err := flags.service()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	type transferResult struct {
+		Source string `json:"source"`
+		files.Transfer
+		Error string `json:"error,omitempty"`
+	}
+
+	// every source is tried even if one fails, the failures are reported with the results
+	sources, destination := paths[:len(paths)-1], paths[len(paths)-1]
+	results := []transferResult{}
+	rows := [][]string{}
+	failed := 0
+	for _, source := range sources {
+		transfer, err := fn(service, ctx, source, destination, *overwrite)
+		result := transferResult{Source: source, Transfer: transfer}
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		results = append(results, result)
+		rows = append(rows, []string{source, transfer.Destination, fmt.Sprint(transfer.Files), formatSize(transfer.Bytes), result.Error})
+	}
+
+	err = flags.print(results, []string{"SOURCE", "DESTINATION", "FILES", "SIZE", "ERROR"}, rows)
+	if err != nil {
+		return err
+	}
+	return failedPaths(failed, len(sources))
+}
+
+// failedPaths is the error of commands that work on several paths when some of them failed
+func failedPaths(failed, total int) error {
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%v of %v paths failed", failed, total)
+}
+
+func trash(args []string) error {
+	fs := newFlagSet("trash", "[flags] <path>... | --list | --restore <name>...")
+	flags := registerFileFlags(fs)
+	list := fs.Bool("list", false, "list the items in the trash")
+	restore := fs.Bool("restore", false, "restore the named items to where they were deleted from")
+	args, err := parseArgs(fs, args, 0, -1)
+	if err != nil {
+		return err
+	}
+	if *list == (len(args) > 0) || (*restore && *list) {
+		err = fmt.Errorf("pass the paths to trash, --list, or --restore with the names of trashed items")
+		fmt.Fprintln(fs.Output(), err)
+		fs.Usage()
+		return usageError{err}
+	}
+
+	ctx, cancel, service, err := flags.service()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if *list {
+		items, err := service.ListTrash(ctx)
+		if err != nil {
+			return err
+		}
+
+		rows := [][]string{}
+		for _, item := range items {
+			rows = append(rows, []string{item.Name, formatTime(item.DeletedAt), item.OriginalPath})
+		}
+		return flags.print(items, []string{"NAME", "DELETED", "ORIGINAL PATH"}, rows)
+	}
+
+	type trashResult struct {
+		Arg string `json:"arg"`
+		files.TrashItem
+		Error string `json:"error,omitempty"`
+	}
+
+	op := service.Trash
+	if *restore {
+		op = service.Restore
+	} else {
+		args, err = absPaths(args)
+		if err != nil {
+			return err
+		}
+	}
+
+	// every path or name is tried even if one fails, the failures are reported with the results
+	results := []trashResult{}
+	rows := [][]string{}
+	failed := 0
+	for _, arg := range args {
+		item, err := op(ctx, arg)
+		result := trashResult{Arg: arg, TrashItem: item}
+		deleted := ""
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		} else {
+			deleted = formatTime(item.DeletedAt)
+		}
+		results = append(results, result)
+		rows = append(rows, []string{arg, item.Name, deleted, item.OriginalPath, result.Error})
+	}
+
+	err = flags.print(results, []string{"ARG", "NAME", "DELETED", "ORIGINAL PATH", "ERROR"}, rows)
+	if err != nil {
+		return err
+	}
+	return failedPaths(failed, len(args))
+}
+
+func tag(args []string) error {
+	fs := newFlagSet("tag", "[flags] <path>...")
+	flags := registerFileFlags(fs)
+	add, remove := stringsFlag{}, stringsFlag{}
+	fs.Var(&add, "add", "tag to add, can be repeated")
+	fs.Var(&remove, "remove", "tag to remove, can be repeated")
+	args, err := parseArgs(fs, args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	paths, err := absPaths(args)
+	if err != nil {
+		return err
+	}
+	_, cancel, service, err := flags.service()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	type pathTags struct {
+		Path string   `json:"path"`
+		Tags []string `json:"tags"`
+	}
+
+	results := []pathTags{}
+	rows := [][]string{}
+	for _, path := range paths {
+		tags, err := service.Tags(path)
+		if err == nil && len(add) > 0 {
+			tags, err = service.AddTags(path, add...)
+		}
+		if err == nil && len(remove) > 0 {
+			tags, err = service.RemoveTags(path, remove...)
+		}
+		if err != nil {
+			return err
+		}
+
+		results = append(results, pathTags{Path: path, Tags: tags})
+		rows = append(rows, []string{path, strings.Join(tags, ", ")})
+	}
+
+	return flags.print(results, []string{"PATH", "TAGS"}, rows)
+}
+
+func search(args []string) error {
+	fs := newFlagSet("search", "[flags] [path]")
+	flags := registerFileFlags(fs)
+	query := files.SearchQuery{}
+	fs.StringVar(&query.Name, "name", "", "glob like *.go or part of the file name, case insensitive")
+	fs.StringVar(&query.Tag, "tag", "", "tag the files must have")
+	fs.IntVar(&query.Limit, "limit", files.DefaultSearchLimit, "maximum number of results")
+	args, err := parseArgs(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	paths, err := absPaths(args)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, service, err := flags.service()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	results, err := service.Search(ctx, paths[0], query)
+	if err != nil {
+		return err
+	}
+
+	return flags.print(results, entryHeader, entryRows(results))
+}
+
+func du(args []string) error {
+	fs := newFlagSet("du", "[flags] [path]")
+	flags := registerFileFlags(fs)
+	top := fs.Int("top", 10, "number of largest files to show")
+	args, err := parseArgs(fs, args, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	paths, err := absPaths(args)
+	if err != nil {
+		return err
+	}
+	ctx, cancel, service, err := flags.service()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	usage, err := service.Usage(ctx, paths[0], *top)
+	if err != nil {
+		return err
+	}
+
+	if !flags.json {
+		fmt.Printf("%v: %v in %v files, %v of %v available on disk\n\n", usage.Path, formatSize(usage.Bytes), usage.Files, formatSize(int64(usage.DiskAvailable)), formatSize(int64(usage.DiskSize)))
+	}
+	return flags.print(usage, entryHeader, entryRows(usage.Largest))
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"golang-web-core/srv/cfg"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const serviceTemplate = `[Unit]
+Description=Linux File Explorer backend
+Requires=%[1]v.socket
+After=%[1]v.socket
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart=%[2]v
+ExecReload=/bin/kill -HUP $MAINPID
+WorkingDirectory=%[3]v
+Restart=on-failure
+WatchdogSec=30
+TimeoutStopSec=%[4]v
+
+[Install]
+WantedBy=default.target
+`
+
+const socketTemplate = `[Unit]
+Description=Linux File Explorer backend socket
+
+[Socket]
+%[1]vSocketMode=0600
+
+[Install]
+WantedBy=sockets.target
+`
+
+// installService writes a systemd user service and socket for the current config. the socket listens on the
+// configured port and unix socket and starts the server on the first connection
+func installService(args []string) error {
+	fs := newFlagSet("install-service", "[flags]")
+	flags := registerConfigFlags(fs)
+	defaultDir := ""
+	if configDir, err := os.UserConfigDir(); err == nil {
+		defaultDir = filepath.Join(configDir, "systemd", "user")
+	}
+	dir := fs.String("dir", defaultDir, "directory to write the units to")
+	force := fs.Bool("force", false, "replace existing units")
+	print := fs.Bool("print", false, "print the units instead of writing them")
+	err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	config, files, err := flags.load()
+	if err != nil {
+		return err
+	}
+
+	service, err := serviceUnit(config, flags.configs, files[0])
+	if err != nil {
+		return err
+	}
+	socket := socketUnit(config)
+
+	units := []struct {
+		name    string
+		content string
+	}{
+		{cfg.AppName + ".service", service},
+		{cfg.AppName + ".socket", socket},
+	}
+
+	if *print {
+		for _, unit := range units {
+			fmt.Printf("# %v\n%v\n", unit.name, unit.content)
+		}
+		return nil
+	}
+
+	if *dir == "" {
+		return errors.New("the systemd user directory could not be determined, pass --dir")
+	}
+	err = os.MkdirAll(*dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	for _, unit := range units {
+		path := filepath.Join(*dir, unit.name)
+		if _, err := os.Stat(path); err == nil && !*force {
+			return fmt.Errorf("%v already exists, pass --force to replace it", path)
+		}
+
+		err = os.WriteFile(path, []byte(unit.content), 0o644)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("wrote %v\n", path)
+	}
+
+	fmt.Printf("\nstart it with:\n  systemctl --user daemon-reload\n  systemctl --user enable --now %v.socket\n", cfg.AppName)
+	return nil
+}
+
+func serviceUnit(config cfg.Config, configs []string, base string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	// the config the units are installed for is the one the service uses, even if $LFE_CONFIG changes later
+	if len(configs) == 0 {
+		configs = []string{base}
+	}
+
+	command := []string{exe, "serve"}
+	for _, path := range configs {
+		if strings.ContainsRune(path, filepath.Separator) {
+			path, err = filepath.Abs(path)
+			if err != nil {
+				return "", err
+			}
+		}
+		command = append(command, "--config", path)
+	}
+
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = quoteUnitArg(arg)
+	}
+
+	// leave some time to log and close the repositories after the drain deadline
+	stopTimeout := config.Timeouts.ShutdownSeconds + 10
+
+	return fmt.Sprintf(serviceTemplate, cfg.AppName, strings.Join(quoted, " "), escapeUnitSpecifiers(wd), stopTimeout), nil
+}
+
+func socketUnit(config cfg.Config) string {
+	listen := ""
+	if !config.DisableTCP {
+		for _, host := range config.Hosts {
+			listen += "ListenStream=" + net.JoinHostPort(host, strconv.Itoa(config.Port)) + "\n"
+		}
+	}
+	if config.UnixSocket.Enabled {
+		listen += "ListenStream=" + escapeUnitSpecifiers(config.UnixSocket.Path) + "\n"
+	}
+
+	return fmt.Sprintf(socketTemplate, listen)
+}
+
+// quoteUnitArg quotes an argument of ExecStart so spaces, quotes, variables and specifiers are kept as they are
+func quoteUnitArg(arg string) string {
+	arg = escapeUnitSpecifiers(arg)
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	arg = strings.ReplaceAll(arg, `"`, `\"`)
+	return `"` + arg + `"`
+}
+
+func escapeUnitSpecifiers(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
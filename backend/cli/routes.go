@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"golang-web-core/controllers"
+	"golang-web-core/srv"
+	"golang-web-core/srv/route"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+)
+
+func routes(args []string) error {
+	fs := newFlagSet("routes", "[flags]")
+	flags := registerConfigFlags(fs).registerServerFlags(fs)
+	err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	config, _, err := flags.load()
+	if err != nil {
+		return err
+	}
+
+	server, err := srv.InspectServer(config)
+	if err != nil {
+		return err
+	}
+
+	sorted := slices.SortedFunc(maps.Values(server.Routes), func(a, b route.Route) int {
+		return cmp.Or(cmp.Compare(a.Pattern, b.Pattern), cmp.Compare(a.Method, b.Method))
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tCONTROLLER\tAUTH")
+	for _, r := range sorted {
+		auth := "token"
+		if controllers.IsPublicPath(r.Pattern) {
+			auth = "public"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", r.Method, r.Pattern, r.ControllerName, auth)
+	}
+	return w.Flush()
+}
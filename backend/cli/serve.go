@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"golang-web-core/srv"
+	"golang-web-core/srv/cfg"
+	"golang-web-core/srv/instance"
+	"golang-web-core/util/logging"
+	"log/slog"
+)
+
+func serve(args []string) error {
+	fs := newFlagSet("serve", "[flags]")
+	flags := registerConfigFlags(fs).registerServerFlags(fs)
+	err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	config, files, err := flags.load()
+	if err != nil {
+		return err
+	}
+
+	logFile, err := logging.Setup(config.Logging.Options())
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	slog.Info("loaded config", "files", files)
+
+	lock, err := instance.Acquire(instance.Dir())
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	server, err := srv.NewServer(config)
+	if err != nil {
+		return err
+	}
+	server.Lock = lock
+	srv.PrintServerConfig(server)
+
+	// a reload reads the same files and keeps the flags
+	server.LoadConfig = func() (cfg.Config, error) {
+		config, _, err := flags.load()
+		return config, err
+	}
+
+	return server.Start()
+}
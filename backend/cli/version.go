@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"fmt"
+	"golang-web-core/srv"
+	"golang-web-core/srv/cfg"
+	"runtime"
+)
+
+func version(args []string) error {
+	err := parse(newFlagSet("version", ""), args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v %v (%v %v/%v)\n", cfg.AppName, srv.Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return nil
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// Close releases the repositories and controllers that hold resources like database connections or watchers
+func (c ApplicationController) Close() error {
+	return c.close(true, true, true)
+}
+
+// CloseReplaced closes what next did not take over from c after a config reload
+func (c ApplicationController) CloseReplaced(next ApplicationController) error {
+	return c.close(
+		!reflect.DeepEqual(c.Config.AppRepository, next.Config.AppRepository),
+		!reflect.DeepEqual(c.Config.FileAssociationRepository, next.Config.FileAssociationRepository),
+		!reflect.DeepEqual(c.Config.ShareLinkRepository, next.Config.ShareLinkRepository),
+	)
+}
+
+// close closes the controllers and the selected repositories
+func (c ApplicationController) close(appRepo, associationRepo, shareLinkRepo bool) error {
+	errs := []error{}
+
+	repos := []struct {
+		close bool
+		repo  any
+	}{
+		{appRepo, c.appRepo},
+		{associationRepo, c.associationRepo},
+		{shareLinkRepo, c.shareLinkRepo},
+	}
+	for _, r := range repos {
+		if closer, ok := r.repo.(io.Closer); ok && r.close {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	for name, cont := range c.Controllers {
+		if name == c.Name() {
+			continue
+		}
+		if closer, ok := cont.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
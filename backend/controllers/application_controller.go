@@ -1,35 +1,99 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"golang-web-core/domain"
-	apprepo "golang-web-core/repositories/app"
-	fileassociationrepo "golang-web-core/repositories/file_association"
+	"golang-web-core/repositories"
+	"golang-web-core/services/files"
+	"golang-web-core/srv/auth"
 	"golang-web-core/srv/cfg"
+	"golang-web-core/srv/middleware"
 	"golang-web-core/util"
+	"golang-web-core/util/jobs"
+	"golang-web-core/util/sandbox"
+	"io/fs"
 	"net/http"
 	"reflect"
+	"slices"
 )
 
+// these paths can be requested without an api token
+var publicPaths = []string{
+	"/favicon.ico",
+	"/healthz",
+	"/readyz",
+}
+
+func IsPublicPath(path string) bool {
+	return slices.Contains(publicPaths, path)
+}
+
 // you shouldn't be touching this file except for the BeforeAction and setupControllers
 
 type ApplicationController struct {
 	cfg.Config
-	Controllers     map[string]Controller
+	Controllers map[string]Controller
+	Sandbox     *sandbox.Sandbox
+	// Jobs tracks copies, extractions and uploads so shutdown can wait for them
+	Jobs *jobs.Tracker
+	// Files is the file service shared with the command line
+	Files           *files.Service
+	Policies        Policies
+	rateLimiter     *middleware.RateLimiter
+	authTokens      []auth.Token
 	appRepo         domain.AppRepository
 	associationRepo domain.FileAssociationRepository
+	shareLinkRepo   domain.ShareLinkRepository
 }
 
 // this verifies that ApplicationController fully implements Controller
 var ApplicationControllerVerifier Controller = ApplicationController{}
 
-func NewApplicationController(config cfg.Config) (ApplicationController, error) {
+// NewApplicationController sets up the repositories and controllers for config. when the config was reloaded,
+// previous is the controller that was serving requests so far: its jobs and the repositories whose settings did
+// not change are taken over
+func NewApplicationController(config cfg.Config, previous *ApplicationController) (ApplicationController, error) {
+	return newApplicationController(config, previous, true)
+}
+
+// InspectApplicationController sets up the repositories and controllers for commands that only look at the
+// config, like check-config and routes. unlike NewApplicationController it doesn't create missing api tokens
+func InspectApplicationController(config cfg.Config) (ApplicationController, error) {
+	return newApplicationController(config, nil, false)
+}
+
+func newApplicationController(config cfg.Config, previous *ApplicationController, createTokens bool) (ApplicationController, error) {
 	cont := ApplicationController{
 		Config:      config,
 		Controllers: map[string]Controller{},
+		Jobs:        jobs.NewTracker(),
+	}
+	if previous != nil {
+		cont.Jobs = previous.Jobs
+	}
+
+	sb, err := sandbox.New(config.AllowedRoots, config.DeniedPaths)
+	if err != nil {
+		return ApplicationController{}, err
+	}
+	cont.Sandbox = sb
+	cont.Files = files.New(sb, cont.Jobs, "")
+
+	err = cont.setupAuthTokens(createTokens)
+	if err != nil {
+		return ApplicationController{}, err
+	}
+
+	// the limiter is taken over so a reload doesn't reset every client's limit, its rate is only updated once
+	// the new config was set up successfully
+	cont.rateLimiter = middleware.NewRateLimiter(config.Limits.RequestsPerSecond, config.Limits.Burst)
+	if previous != nil {
+		cont.rateLimiter = previous.rateLimiter
 	}
+	cont.Policies = NewPolicies(config, cont.rateLimiter)
 
-	err := cont.setupRepositories()
+	err = cont.setupRepositories(previous)
 	if err != nil {
 		return ApplicationController{}, err
 	}
@@ -39,6 +103,8 @@ func NewApplicationController(config cfg.Config) (ApplicationController, error)
 		return ApplicationController{}, err
 	}
 
+	cont.rateLimiter.SetRate(config.Limits.RequestsPerSecond, config.Limits.Burst)
+
 	return cont, err
 }
 
@@ -47,16 +113,13 @@ func (c ApplicationController) Name() string {
 }
 
 func (c ApplicationController) BeforeAction(handler http.HandlerFunc) http.HandlerFunc {
+	authenticated := middleware.Authenticate(c.authTokens, IsPublicPath)(handler)
+
 	return func(rw http.ResponseWriter, req *http.Request) {
 		// any checks you want to do on every single request that goes into the server can go here
 
 		// this line initiates the next step in the request process.
-		// if you wanted to throw an error here or something, it might look something like this:
-		// if (someCondition) {
-		// 	http.Error(rw, "This is a test internal server error", http.StatusInternalServerError)
-		// 	return
-		// }
-		handler(rw, req)
+		authenticated(rw, req)
 	}
 }
 
@@ -64,19 +127,63 @@ func (c ApplicationController) Favicon(rw http.ResponseWriter, req *http.Request
 	http.ServeFile(rw, req, "favicon.ico")
 }
 
-func (c *ApplicationController) setupRepositories() error {
-	switch c.Config.AppRepository.Type {
-	case "MockAppRepository":
-		c.appRepo = apprepo.MockAppRepository{}
-	default:
-		return fmt.Errorf("unknown app repository type: %v", c.Config.AppRepository.Type)
+// setupAuthTokens loads the api tokens. missing token files are created unless create is false, in which case
+// their tokens are left out
+func (c *ApplicationController) setupAuthTokens(create bool) error {
+	load := auth.LoadOrCreateToken
+	if !create {
+		load = auth.LoadToken
+	}
+
+	token, err := load(c.Config.AuthTokenPath)
+	if err == nil {
+		c.authTokens = []auth.Token{{Name: "main", Value: token, Scopes: []string{auth.ScopeAll}}}
+	} else if create || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for _, scoped := range c.Config.AuthTokens {
+		token, err := load(scoped.Path)
+		if !create && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not load auth token %v: %v", scoped.Name, err)
+		}
+		c.authTokens = append(c.authTokens, auth.Token{Name: scoped.Name, Value: token, Scopes: scoped.Scopes})
 	}
 
-	switch c.Config.FileAssociationRepository.Type {
-	case "MockFileAssociationRepository":
-		c.associationRepo = fileassociationrepo.MockFileAssociationRepository{}
-	default:
-		return fmt.Errorf("unknown file association repository type: %v", c.Config.FileAssociationRepository.Type)
+	return nil
+}
+
+func (c *ApplicationController) setupRepositories(previous *ApplicationController) error {
+	var err error
+
+	if previous != nil && reflect.DeepEqual(previous.Config.AppRepository, c.Config.AppRepository) {
+		c.appRepo = previous.appRepo
+	} else {
+		c.appRepo, err = repositories.Apps.New(c.Config.AppRepository.Type, c.Config.AppRepository.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	if previous != nil && reflect.DeepEqual(previous.Config.FileAssociationRepository, c.Config.FileAssociationRepository) {
+		c.associationRepo = previous.associationRepo
+	} else {
+		c.associationRepo, err = repositories.FileAssociations.New(c.Config.FileAssociationRepository.Type, c.Config.FileAssociationRepository.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	if previous != nil && reflect.DeepEqual(previous.Config.ShareLinkRepository, c.Config.ShareLinkRepository) {
+		c.shareLinkRepo = previous.shareLinkRepo
+	} else {
+		c.shareLinkRepo, err = repositories.ShareLinks.New(c.Config.ShareLinkRepository.Type, c.Config.ShareLinkRepository.Config)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -85,9 +192,23 @@ func (c *ApplicationController) setupRepositories() error {
 func (c *ApplicationController) setupControllers() error {
 	controllers := []Controller{
 		c,
-		// this is where you initialize your controllers. if you do not initialize your controllers here, they will not be usable
-		NewAppsController(c.appRepo),
-		NewAssociationsController(c.associationRepo),
+		// this is where you initialize your controllers. if you do not initialize your controllers here, they will not be usable.
+		// the routes of every controller implementing RouteProvider are registered automatically
+		NewAppsController(c.appRepo, c.Policies),
+		NewAssociationsController(c.associationRepo, c.Policies),
+		NewShareLinksController(c.shareLinkRepo, c.Sandbox, c.Config.ShareLinks, c.Policies),
+		NewFileSystemController(c.Files, c.Policies),
+		NewMetricsController(c.Policies),
+		NewHealthController(
+			map[string]any{
+				"appRepository":             c.appRepo,
+				"fileAssociationRepository": c.associationRepo,
+				"shareLinkRepository":       c.shareLinkRepo,
+			},
+			c.Config.AllowedRoots,
+			c.Controllers,
+			c.authTokens,
+		),
 	}
 
 	// everything below here should be left untouched
@@ -1,20 +1,20 @@
 package controllers
 
 import (
-	"encoding/json"
+	"context"
 	"golang-web-core/domain"
 	"golang-web-core/srv/route"
-	"golang-web-core/srv/srverr"
 	"net/http"
 	"reflect"
 )
 
 type AppsController struct {
-	appRepo domain.AppRepository
+	appRepo  domain.AppRepository
+	policies Policies
 }
 
-func NewAppsController(appRepo domain.AppRepository) AppsController {
-	return AppsController{appRepo: appRepo}
+func NewAppsController(appRepo domain.AppRepository, policies Policies) AppsController {
+	return AppsController{appRepo: appRepo, policies: policies}
 }
 
 // BeforeAction implements Controller.
@@ -29,30 +29,31 @@ func (a AppsController) Name() string {
 	return reflect.TypeOf(a).Name()
 }
 
+// RoutePrefix implements PrefixedRouteProvider.
+func (a AppsController) RoutePrefix() string {
+	return "/api/apps"
+}
+
+// Routes implements RouteProvider.
 func (a AppsController) Routes() []route.Route {
 	return []route.Route{
 		{
-			Pattern:        "/api/apps",
+			Pattern:        "",
 			Method:         http.MethodGet,
-			Handler:        a.GetAllApps,
+			Handler:        route.Typed(a.GetAllApps),
+			Name:           "GetAllApps",
 			ControllerName: a.Name(),
+			Middlewares:    a.policies.Read,
+			Summary:        "Get all installed apps",
+			Response:       []domain.App{},
 		},
 	}
 }
 
 // Get all apps
-func (a AppsController) GetAllApps(w http.ResponseWriter, r *http.Request) {
-	apps, err := a.appRepo.GetAllApps()
-	if err != nil {
-		srverr.Handle500(w, err)
-		return
-	}
-
-	err = json.NewEncoder(w).Encode(apps)
-	if err != nil {
-		srverr.Handle500(w, err)
-		return
-	}
+func (a AppsController) GetAllApps(ctx context.Context, _ route.Empty) ([]domain.App, error) {
+	return a.appRepo.GetAllApps()
 }
 
 var _ Controller = AppsController{}
+var _ PrefixedRouteProvider = AppsController{}
@@ -1,21 +1,20 @@
 package controllers
 
 import (
-	"encoding/json"
+	"context"
 	"golang-web-core/domain"
 	"golang-web-core/srv/route"
-	"golang-web-core/srv/srverr"
-	"golang-web-core/util"
 	"net/http"
 	"reflect"
 )
 
 type AssociationsController struct {
 	associationRepo domain.FileAssociationRepository
+	policies        Policies
 }
 
-func NewAssociationsController(associationRepo domain.FileAssociationRepository) AssociationsController {
-	return AssociationsController{associationRepo: associationRepo}
+func NewAssociationsController(associationRepo domain.FileAssociationRepository, policies Policies) AssociationsController {
+	return AssociationsController{associationRepo: associationRepo, policies: policies}
 }
 
 // BeforeAction implements Controller.
@@ -30,77 +29,65 @@ func (a AssociationsController) Name() string {
 	return reflect.TypeOf(a).Name()
 }
 
+// RoutePrefix implements PrefixedRouteProvider.
+func (a AssociationsController) RoutePrefix() string {
+	return "/api/associations"
+}
+
+// Routes implements RouteProvider.
 func (a AssociationsController) Routes() []route.Route {
 	return []route.Route{
 		{
-			Pattern:        "/api/associations",
+			Pattern:        "",
 			Method:         http.MethodGet,
-			Handler:        a.GetAllAssociations,
+			Handler:        route.Typed(a.GetAllAssociations),
+			Name:           "GetAllAssociations",
 			ControllerName: a.Name(),
+			Middlewares:    a.policies.Read,
+			Summary:        "Get all file associations",
+			Response:       []domain.FileAssociation{},
 		},
 		{
-			Pattern:        "/api/associations",
+			Pattern:        "",
 			Method:         http.MethodPost,
-			Handler:        a.CreateAssociation,
+			Handler:        route.Typed(a.CreateAssociation),
+			Name:           "CreateAssociation",
 			ControllerName: a.Name(),
+			Middlewares:    a.policies.Write,
+			Summary:        "Create a file association",
+			Request:        domain.FileAssociation{},
+			Response:       domain.FileAssociation{},
 		},
 		{
-			Pattern:        "/api/associations/{id}",
+			Pattern:        "/{id}",
 			Method:         http.MethodDelete,
-			Handler:        a.DeleteAssociation,
+			Handler:        route.Typed(a.DeleteAssociation),
+			Name:           "DeleteAssociation",
 			ControllerName: a.Name(),
+			Middlewares:    a.policies.Write,
+			Summary:        "Delete a file association",
 		},
 	}
 }
 
 // Get all associations
-func (a AssociationsController) GetAllAssociations(w http.ResponseWriter, r *http.Request) {
-	associations, err := a.associationRepo.GetAllAssociations()
-	if err != nil {
-		srverr.Handle500(w, err)
-		return
-	}
-
-	err = json.NewEncoder(w).Encode(associations)
-	if err != nil {
-		srverr.Handle500(w, err)
-		return
-	}
+func (a AssociationsController) GetAllAssociations(ctx context.Context, _ route.Empty) ([]domain.FileAssociation, error) {
+	return a.associationRepo.GetAllAssociations()
 }
 
 // Create an association
-func (a AssociationsController) CreateAssociation(w http.ResponseWriter, r *http.Request) {
-	var association domain.FileAssociation
-	err := util.DecodeContextParams(r, &association)
-	if err != nil {
-		srverr.Handle400(w, err)
-		return
-	}
-
-	association, err = a.associationRepo.CreateAssociation(association)
-	if err != nil {
-		srverr.Handle500(w, err)
-		return
-	}
+func (a AssociationsController) CreateAssociation(ctx context.Context, association domain.FileAssociation) (domain.FileAssociation, error) {
+	return a.associationRepo.CreateAssociation(association)
+}
 
-	err = json.NewEncoder(w).Encode(association)
-	if err != nil {
-		srverr.Handle500(w, err)
-		return
-	}
+type associationIDParams struct {
+	ID string `path:"id" json:"-" validate:"required"`
 }
 
 // Delete an association
-func (a AssociationsController) DeleteAssociation(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-
-	err := a.associationRepo.DeleteAssociation(id)
-	if err != nil {
-		srverr.Handle500(w, err)
-		return
-	}
-
-	w.WriteHeader(http.StatusNoContent)
+func (a AssociationsController) DeleteAssociation(ctx context.Context, params associationIDParams) (route.Empty, error) {
+	return route.Empty{}, a.associationRepo.DeleteAssociation(params.ID)
 }
 
 var _ Controller = AssociationsController{}
+var _ PrefixedRouteProvider = AssociationsController{}
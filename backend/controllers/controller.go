@@ -1,8 +1,23 @@
 package controllers
 
-import "net/http"
+import (
+	"golang-web-core/srv/route"
+	"net/http"
+)
 
 type Controller interface {
 	Name() string
 	BeforeAction(handler http.HandlerFunc) http.HandlerFunc
 }
+
+// RouteProvider is implemented by controllers that serve routes. the routes of every controller registered in
+// ApplicationController.setupControllers that implements it are registered automatically
+type RouteProvider interface {
+	Routes() []route.Route
+}
+
+// PrefixedRouteProvider is a RouteProvider whose route patterns are all relative to a common path prefix
+type PrefixedRouteProvider interface {
+	RouteProvider
+	RoutePrefix() string
+}
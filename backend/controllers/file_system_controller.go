@@ -1,11 +1,24 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"golang-web-core/services/files"
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"golang-web-core/util/jobs"
+	"log/slog"
 	"net/http"
 	"reflect"
 )
 
 type FileSystemController struct {
+	files    *files.Service
+	policies Policies
+}
+
+func NewFileSystemController(service *files.Service, policies Policies) FileSystemController {
+	return FileSystemController{files: service, policies: policies}
 }
 
 // BeforeAction implements Controller.
@@ -20,7 +33,151 @@ func (f FileSystemController) Name() string {
 	return reflect.TypeOf(f).Name()
 }
 
+// RoutePrefix implements PrefixedRouteProvider.
+func (f FileSystemController) RoutePrefix() string {
+	return "/api/files"
+}
+
+// Routes implements RouteProvider.
+func (f FileSystemController) Routes() []route.Route {
+	return []route.Route{
+		{
+			Pattern:        "",
+			Method:         http.MethodGet,
+			Handler:        route.Typed(f.ListFiles),
+			Name:           "ListFiles",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Read,
+			Summary:        "List the files and folders in a folder",
+			Request:        listFilesParams{},
+			Response:       []files.Entry{},
+		},
+		{
+			Pattern:        "/copy",
+			Method:         http.MethodPost,
+			Handler:        route.Typed(f.CopyFiles),
+			Name:           "CopyFiles",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Write,
+			Summary:        "Copy a file or folder",
+			Request:        transferParams{},
+			Response:       files.Transfer{},
+		},
+		{
+			Pattern:        "/move",
+			Method:         http.MethodPost,
+			Handler:        route.Typed(f.MoveFiles),
+			Name:           "MoveFiles",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Write,
+			Summary:        "Move a file or folder",
+			Request:        transferParams{},
+			Response:       files.Transfer{},
+		},
+		{
+			Pattern:        "/search",
+			Method:         http.MethodGet,
+			Handler:        route.Typed(f.SearchFiles),
+			Name:           "SearchFiles",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Read,
+			Summary:        "Search the files below a folder by name or tag",
+			Request:        searchParams{},
+			Response:       []files.Entry{},
+		},
+		{
+			Pattern:        "/usage",
+			Method:         http.MethodGet,
+			Handler:        route.Typed(f.GetUsage),
+			Name:           "GetUsage",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Read,
+			Summary:        "Get the size of a folder and its largest files",
+			Request:        usageParams{},
+			Response:       files.Usage{},
+		},
+		{
+			Pattern:        "/tags",
+			Method:         http.MethodGet,
+			Handler:        route.Typed(f.GetTags),
+			Name:           "GetTags",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Read,
+			Summary:        "Get the tags of a file or folder",
+			Request:        pathParams{},
+			Response:       []string{},
+		},
+		{
+			Pattern:        "/tags",
+			Method:         http.MethodPost,
+			Handler:        route.Typed(f.AddTags),
+			Name:           "AddTags",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Write,
+			Summary:        "Assign tags to a file or folder",
+			Request:        addTagsParams{},
+			Response:       []string{},
+		},
+		{
+			Pattern:        "/tags",
+			Method:         http.MethodDelete,
+			Handler:        route.Typed(f.RemoveTags),
+			Name:           "RemoveTags",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Write,
+			Summary:        "Remove tags from a file or folder",
+			Request:        removeTagsParams{},
+			Response:       []string{},
+		},
+		{
+			Pattern:        "/trash",
+			Method:         http.MethodGet,
+			Handler:        route.Typed(f.ListTrash),
+			Name:           "ListTrash",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Read,
+			Summary:        "List the items in the trash",
+			Response:       []files.TrashItem{},
+		},
+		{
+			Pattern:        "/trash",
+			Method:         http.MethodPost,
+			Handler:        route.Typed(f.TrashFiles),
+			Name:           "TrashFiles",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Write,
+			Summary:        "Move files and folders to the trash",
+			Request:        trashParams{},
+			Response:       []trashResult{},
+		},
+		{
+			Pattern:        "/trash/{name}/restore",
+			Method:         http.MethodPost,
+			Handler:        route.Typed(f.RestoreTrash),
+			Name:           "RestoreTrash",
+			ControllerName: f.Name(),
+			Middlewares:    f.policies.Write,
+			Summary:        "Restore an item from the trash to where it was deleted from",
+			Request:        restoreTrashParams{},
+			Response:       files.TrashItem{},
+		},
+	}
+}
+
+type pathParams struct {
+	Path string `query:"path" json:"-" validate:"required"`
+}
+
+type listFilesParams struct {
+	Path   string `query:"path" json:"-" validate:"required"`
+	Hidden bool   `query:"hidden" json:"-"`
+}
+
 // Read files and folders from a directory
+func (f FileSystemController) ListFiles(ctx context.Context, params listFilesParams) ([]files.Entry, error) {
+	entries, err := f.files.List(ctx, params.Path, params.Hidden)
+	return entries, fileError(err)
+}
 
 // Read a file
 
@@ -32,16 +189,143 @@ func (f FileSystemController) Name() string {
 
 // Upload files
 
+type transferParams struct {
+	Source      string `json:"source" validate:"required"`
+	Destination string `json:"destination" validate:"required"`
+	Overwrite   bool   `json:"overwrite"`
+}
+
 // Move files and folders
+func (f FileSystemController) MoveFiles(ctx context.Context, params transferParams) (files.Transfer, error) {
+	transfer, err := f.files.Move(ctx, params.Source, params.Destination, params.Overwrite)
+	return transfer, fileError(err)
+}
 
 // Copy files and folders
+func (f FileSystemController) CopyFiles(ctx context.Context, params transferParams) (files.Transfer, error) {
+	transfer, err := f.files.Copy(ctx, params.Source, params.Destination, params.Overwrite)
+	return transfer, fileError(err)
+}
+
+type searchParams struct {
+	Path  string `query:"path" json:"-" validate:"required"`
+	Name  string `query:"name" json:"-"`
+	Tag   string `query:"tag" json:"-"`
+	Limit int    `query:"limit" json:"-" validate:"min=0"`
+}
+
+// Search files by name or tag
+func (f FileSystemController) SearchFiles(ctx context.Context, params searchParams) ([]files.Entry, error) {
+	results, err := f.files.Search(ctx, params.Path, files.SearchQuery{Name: params.Name, Tag: params.Tag, Limit: params.Limit})
+	return results, fileError(err)
+}
+
+type usageParams struct {
+	Path string `query:"path" json:"-" validate:"required"`
+	Top  int    `query:"top" json:"-" validate:"min=0,max=1000"`
+}
 
 // Get top n number of files by size in a directory
+func (f FileSystemController) GetUsage(ctx context.Context, params usageParams) (files.Usage, error) {
+	top := params.Top
+	if top == 0 {
+		top = 10
+	}
+
+	usage, err := f.files.Usage(ctx, params.Path, top)
+	return usage, fileError(err)
+}
+
+// Get the tags of a file or folder
+func (f FileSystemController) GetTags(ctx context.Context, params pathParams) ([]string, error) {
+	tags, err := f.files.Tags(params.Path)
+	return tags, fileError(err)
+}
+
+type addTagsParams struct {
+	Path string   `json:"path" validate:"required"`
+	Tags []string `json:"tags" validate:"required,min=1"`
+}
 
 // Assign a tag to a file or folder
+func (f FileSystemController) AddTags(ctx context.Context, params addTagsParams) ([]string, error) {
+	tags, err := f.files.AddTags(params.Path, params.Tags...)
+	return tags, fileError(err)
+}
+
+type removeTagsParams struct {
+	Path string   `query:"path" json:"-" validate:"required"`
+	Tags []string `query:"tag" json:"-" validate:"required,min=1"`
+}
 
 // Remove a tag from a file or folder
+func (f FileSystemController) RemoveTags(ctx context.Context, params removeTagsParams) ([]string, error) {
+	tags, err := f.files.RemoveTags(params.Path, params.Tags...)
+	return tags, fileError(err)
+}
+
+// Get all files with a given tag, see SearchFiles
+
+// List the items in the trash
+func (f FileSystemController) ListTrash(ctx context.Context, _ route.Empty) ([]files.TrashItem, error) {
+	items, err := f.files.ListTrash(ctx)
+	return items, fileError(err)
+}
+
+type trashParams struct {
+	Paths []string `json:"paths" validate:"required,min=1"`
+}
 
-// Get all files with a given tag
+// trashResult is what happened to one of the paths given to TrashFiles, either Item or Error is set
+type trashResult struct {
+	Path  string                `json:"path"`
+	Item  *files.TrashItem      `json:"item,omitempty"`
+	Error *srverr.ErrorResponse `json:"error,omitempty"`
+}
+
+// Move files and folders to the trash. every path is tried, so the result lists the items that were trashed
+// next to the paths that couldn't be
+func (f FileSystemController) TrashFiles(ctx context.Context, params trashParams) ([]trashResult, error) {
+	results := []trashResult{}
+	for _, path := range params.Paths {
+		item, err := f.files.Trash(ctx, path)
+		if err != nil {
+			srvErr := srverr.FromError(fileError(err))
+			slog.WarnContext(ctx, "could not trash a file", "path", path, "status", srvErr.Code, "error", err)
+
+			response := srvErr.Response()
+			results = append(results, trashResult{Path: path, Error: &response})
+			continue
+		}
+		results = append(results, trashResult{Path: path, Item: &item})
+	}
+	return results, nil
+}
+
+type restoreTrashParams struct {
+	Name string `path:"name" json:"-" validate:"required"`
+}
+
+// Restore an item from the trash
+func (f FileSystemController) RestoreTrash(ctx context.Context, params restoreTrashParams) (files.TrashItem, error) {
+	item, err := f.files.Restore(ctx, params.Name)
+	return item, fileError(err)
+}
+
+// fileError maps the errors of the file service that aren't os errors to status codes
+func fileError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, files.ErrIntoItself), errors.Is(err, files.ErrInvalidTag):
+		return srverr.Wrap(err, http.StatusBadRequest)
+	case errors.Is(err, files.ErrNoTagSupport):
+		return srverr.Public(files.ErrNoTagSupport, http.StatusNotImplemented)
+	case errors.Is(err, jobs.ErrShuttingDown):
+		return srverr.Public(jobs.ErrShuttingDown, http.StatusServiceUnavailable)
+	}
+	return err
+}
 
 var _ Controller = FileSystemController{}
+var _ PrefixedRouteProvider = FileSystemController{}
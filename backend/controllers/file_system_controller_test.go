@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"golang-web-core/srv/openapi"
+	"slices"
+	"testing"
+)
+
+func TestFileSystemRouteParameters(t *testing.T) {
+	doc := openapi.Generate("test", "test", FileSystemController{}.Routes(), nil)
+
+	operations := map[string]openapi.Operation{}
+	for _, path := range doc.Paths {
+		for _, op := range path {
+			operations[op.OperationID] = op
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		operationID string
+		in          string
+		expected    []string
+		required    []string
+		body        bool
+	}{
+		{
+			name:        "List Files",
+			operationID: "ListFiles",
+			expected:    []string{"path", "hidden"},
+			required:    []string{"path"},
+		},
+		{
+			name:        "Search Files",
+			operationID: "SearchFiles",
+			expected:    []string{"path", "name", "tag", "limit"},
+			required:    []string{"path"},
+		},
+		{
+			name:        "Usage",
+			operationID: "GetUsage",
+			expected:    []string{"path", "top"},
+			required:    []string{"path"},
+		},
+		{
+			name:        "Remove Tags",
+			operationID: "RemoveTags",
+			expected:    []string{"path", "tag"},
+			required:    []string{"path", "tag"},
+		},
+		{
+			name:        "Restore Trash",
+			operationID: "RestoreTrash",
+			in:          "path",
+			expected:    []string{"name"},
+			required:    []string{"name"},
+		},
+		{
+			name:        "Body Fields Are Not Parameters",
+			operationID: "CopyFiles",
+			expected:    []string{},
+			required:    []string{},
+			body:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			op, ok := operations[tc.operationID]
+			if !ok {
+				t.Fatalf("operation %v is missing", tc.operationID)
+			}
+
+			in := tc.in
+			if in == "" {
+				in = "query"
+			}
+
+			names := []string{}
+			required := []string{}
+			for _, param := range op.Parameters {
+				if param.In != in {
+					t.Errorf("parameter %v is in %v, expected %v", param.Name, param.In, in)
+				}
+				names = append(names, param.Name)
+				if param.Required {
+					required = append(required, param.Name)
+				}
+			}
+
+			if !slices.Equal(names, tc.expected) {
+				t.Errorf("expected parameters %v, got %v", tc.expected, names)
+			}
+			if !slices.Equal(required, tc.required) {
+				t.Errorf("expected required parameters %v, got %v", tc.required, required)
+			}
+			if (op.RequestBody != nil) != tc.body {
+				t.Errorf("expected a request body %v, got %v", tc.body, op.RequestBody != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"encoding/json"
+	"golang-web-core/srv/auth"
+	"golang-web-core/srv/route"
+	"golang-web-core/util/health"
+	"log/slog"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// minFreeDiskBytes is the free space each allowed root needs for the server to be ready
+const minFreeDiskBytes = 100 << 20
+
+var processStarted = time.Now()
+
+type HealthController struct {
+	repositories map[string]any
+	roots        []string
+	controllers  map[string]Controller
+	authTokens   []auth.Token
+}
+
+// NewHealthController checks the repositories and controllers implementing health.Checker and the free space of
+// the allowed roots. controllers is read on every request, so it may be filled after the controller is created
+func NewHealthController(repositories map[string]any, roots []string, controllers map[string]Controller, authTokens []auth.Token) HealthController {
+	return HealthController{repositories: repositories, roots: roots, controllers: controllers, authTokens: authTokens}
+}
+
+// BeforeAction implements Controller.
+func (h HealthController) BeforeAction(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r)
+	}
+}
+
+// Name implements Controller.
+func (h HealthController) Name() string {
+	return reflect.TypeOf(h).Name()
+}
+
+// Routes implements RouteProvider.
+func (h HealthController) Routes() []route.Route {
+	return []route.Route{
+		{
+			Pattern:        "/healthz",
+			Method:         http.MethodGet,
+			Handler:        h.Healthz,
+			ControllerName: h.Name(),
+			Summary:        "Check that the server is up",
+			Response:       healthResponse{},
+			Status:         http.StatusOK,
+		},
+		{
+			Pattern:        "/readyz",
+			Method:         http.MethodGet,
+			Handler:        h.Readyz,
+			ControllerName: h.Name(),
+			Summary:        "Check that the repositories and disks the server depends on are usable, 503 when they are not",
+			Response:       health.Report{},
+			Status:         http.StatusOK,
+		},
+	}
+}
+
+type healthResponse struct {
+	Status        string  `json:"status"`
+	UptimeSeconds float64 `json:"uptimeSeconds"`
+}
+
+// Healthz reports that the process is up and serving requests
+func (h HealthController) Healthz(rw http.ResponseWriter, req *http.Request) {
+	writeJSON(rw, http.StatusOK, healthResponse{Status: health.StatusOK, UptimeSeconds: time.Since(processStarted).Seconds()})
+}
+
+// Readyz runs the readiness checks and responds with 503 when any of them fails. the errors are only included
+// for authenticated callers since they can contain hostnames
+func (h HealthController) Readyz(rw http.ResponseWriter, req *http.Request) {
+	report := health.Run(req.Context(), health.DefaultTimeout, h.checks())
+
+	if _, err := auth.Authenticate(req, h.authTokens); err != nil {
+		for i := range report.Checks {
+			report.Checks[i].Error = ""
+		}
+	}
+
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(rw, status, report)
+}
+
+func (h HealthController) checks() []health.Check {
+	checks := []health.Check{}
+
+	for _, name := range slices.Sorted(maps.Keys(h.repositories)) {
+		if checker, ok := h.repositories[name].(health.Checker); ok {
+			checks = append(checks, health.Check{Name: name, Checker: checker})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(h.controllers)) {
+		if checker, ok := h.controllers[name].(health.Checker); ok {
+			checks = append(checks, health.Check{Name: name, Checker: checker})
+		}
+	}
+
+	for _, root := range h.roots {
+		checks = append(checks, health.Check{Name: "disk " + root, Checker: health.FreeSpace(root, minFreeDiskBytes)})
+	}
+
+	return checks
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(status)
+	err := json.NewEncoder(rw).Encode(v)
+	if err != nil {
+		slog.Warn("could not encode response", "error", err)
+	}
+}
+
+var _ Controller = HealthController{}
+var _ RouteProvider = HealthController{}
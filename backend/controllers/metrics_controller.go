@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"golang-web-core/util/metrics"
+	"net/http"
+	"reflect"
+)
+
+type MetricsController struct {
+	policies Policies
+}
+
+func NewMetricsController(policies Policies) MetricsController {
+	return MetricsController{policies: policies}
+}
+
+// BeforeAction implements Controller.
+func (m MetricsController) BeforeAction(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r)
+	}
+}
+
+// Name implements Controller.
+func (m MetricsController) Name() string {
+	return reflect.TypeOf(m).Name()
+}
+
+// Routes implements RouteProvider.
+func (m MetricsController) Routes() []route.Route {
+	return []route.Route{
+		{
+			Pattern:        "/metrics",
+			Method:         http.MethodGet,
+			Handler:        m.Metrics,
+			ControllerName: m.Name(),
+			Middlewares:    m.policies.Metrics,
+			Summary:        "Get prometheus metrics",
+			Status:         http.StatusOK,
+		},
+	}
+}
+
+func (m MetricsController) Metrics(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	err := metrics.Default.WriteText(rw)
+	if err != nil {
+		srverr.Handle500(rw, err)
+		return
+	}
+}
+
+var _ Controller = MetricsController{}
+var _ RouteProvider = MetricsController{}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"golang-web-core/srv/auth"
+	"golang-web-core/srv/cfg"
+	"golang-web-core/srv/middleware"
+	"golang-web-core/srv/route"
+)
+
+// Policies are the middleware chains controllers attach to their routes
+type Policies struct {
+	// Read is for routes that only read data. their params are bound from the body as well, so its size is limited
+	Read []route.Middleware
+	// Write is for routes that create, modify or delete data. they are rate limited and rejected in read-only mode
+	Write []route.Middleware
+	// Metrics is for the prometheus endpoint
+	Metrics []route.Middleware
+}
+
+// NewPolicies builds the middleware chains for config. limiter is passed in rather than created here so it can be
+// kept across config reloads
+func NewPolicies(config cfg.Config, limiter *middleware.RateLimiter) Policies {
+	readOnly := func() bool {
+		return config.ReadOnly
+	}
+
+	return Policies{
+		Read: []route.Middleware{
+			middleware.RequireScope(auth.ScopeRead),
+			middleware.MaxBodySize(config.Limits.MaxBodyBytes()),
+		},
+		Write: []route.Middleware{
+			middleware.RequireScope(auth.ScopeWrite),
+			middleware.ReadOnly(readOnly),
+			middleware.RateLimit(limiter),
+			middleware.MaxBodySize(config.Limits.MaxBodyBytes()),
+		},
+		Metrics: []route.Middleware{
+			middleware.RequireScope(auth.ScopeMetrics),
+		},
+	}
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-web-core/domain"
+	"golang-web-core/srv/cfg"
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"golang-web-core/util"
+	"golang-web-core/util/sandbox"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ShareLinksController struct {
+	shareLinkRepo domain.ShareLinkRepository
+	sandbox       *sandbox.Sandbox
+	secret        []byte
+	defaultTTL    time.Duration
+	maxTTL        time.Duration
+	policies      Policies
+}
+
+func NewShareLinksController(shareLinkRepo domain.ShareLinkRepository, sb *sandbox.Sandbox, config cfg.ShareLinks, policies Policies) ShareLinksController {
+	return ShareLinksController{
+		shareLinkRepo: shareLinkRepo,
+		policies:      policies,
+		sandbox:       sb,
+		secret:        []byte(config.Secret),
+		defaultTTL:    time.Duration(config.DefaultTTLSeconds) * time.Second,
+		maxTTL:        time.Duration(config.MaxTTLSeconds) * time.Second,
+	}
+}
+
+// BeforeAction implements Controller.
+func (s ShareLinksController) BeforeAction(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r)
+	}
+}
+
+// Name implements Controller.
+func (s ShareLinksController) Name() string {
+	return reflect.TypeOf(s).Name()
+}
+
+// RoutePrefix implements PrefixedRouteProvider.
+func (s ShareLinksController) RoutePrefix() string {
+	return "/api/shares"
+}
+
+// Routes implements RouteProvider.
+func (s ShareLinksController) Routes() []route.Route {
+	return []route.Route{
+		{
+			Pattern:        "",
+			Method:         http.MethodGet,
+			Handler:        route.Typed(s.GetAllShareLinks),
+			Name:           "GetAllShareLinks",
+			ControllerName: s.Name(),
+			Middlewares:    s.policies.Read,
+			Summary:        "Get all share links",
+			Response:       []domain.ShareLink{},
+		},
+		{
+			Pattern:        "",
+			Method:         http.MethodPost,
+			Handler:        route.Typed(s.CreateShareLink, http.StatusCreated),
+			Name:           "CreateShareLink",
+			ControllerName: s.Name(),
+			Middlewares:    s.policies.Write,
+			Summary:        "Create an expiring download link for a file or folder",
+			Request:        createShareLinkParams{},
+			Response:       shareLinkResponse{},
+			Status:         http.StatusCreated,
+		},
+		{
+			Pattern:        "/{id}",
+			Method:         http.MethodDelete,
+			Handler:        route.Typed(s.RevokeShareLink),
+			Name:           "RevokeShareLink",
+			ControllerName: s.Name(),
+			Middlewares:    s.policies.Write,
+			Summary:        "Revoke a share link",
+		},
+	}
+}
+
+type createShareLinkParams struct {
+	Path         string `json:"path" validate:"required"`
+	TTLSeconds   int    `json:"ttlSeconds" validate:"min=0"`
+	MaxDownloads int    `json:"maxDownloads" validate:"min=0"`
+}
+
+type shareLinkResponse struct {
+	domain.ShareLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// Get all share links
+func (s ShareLinksController) GetAllShareLinks(ctx context.Context, _ route.Empty) ([]domain.ShareLink, error) {
+	return s.shareLinkRepo.GetAllShareLinks()
+}
+
+// Create a share link for a file or folder
+func (s ShareLinksController) CreateShareLink(ctx context.Context, params createShareLinkParams) (shareLinkResponse, error) {
+	ttl := s.defaultTTL
+	if params.TTLSeconds > 0 {
+		ttl = time.Duration(params.TTLSeconds) * time.Second
+	}
+	if ttl > s.maxTTL {
+		return shareLinkResponse{}, srverr.New(fmt.Sprintf("ttlSeconds may not exceed %v", int(s.maxTTL.Seconds())), http.StatusBadRequest)
+	}
+
+	path, err := s.sandbox.Resolve(params.Path)
+	if err != nil {
+		if errors.Is(err, sandbox.ErrOutsideSandbox) {
+			return shareLinkResponse{}, err
+		}
+		return shareLinkResponse{}, srverr.Wrap(err, http.StatusBadRequest)
+	}
+
+	info, err := s.sandbox.Stat(path)
+	if err != nil {
+		return shareLinkResponse{}, err
+	}
+
+	now := time.Now()
+	link, err := s.shareLinkRepo.CreateShareLink(domain.ShareLink{
+		Path:         path,
+		IsDirectory:  info.IsDir(),
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(ttl),
+		MaxDownloads: params.MaxDownloads,
+	})
+	if err != nil {
+		return shareLinkResponse{}, err
+	}
+
+	token := s.token(link)
+	return shareLinkResponse{ShareLink: link, Token: token, URL: "/share/" + token}, nil
+}
+
+type shareLinkIDParams struct {
+	ID string `path:"id" json:"-" validate:"required"`
+}
+
+// Revoke a share link
+func (s ShareLinksController) RevokeShareLink(ctx context.Context, params shareLinkIDParams) (route.Empty, error) {
+	err := s.shareLinkRepo.RevokeShareLink(params.ID)
+	if errors.Is(err, domain.ErrShareLinkNotFound) {
+		return route.Empty{}, srverr.Wrap(err, http.StatusNotFound)
+	}
+	return route.Empty{}, err
+}
+
+// Resolve verifies a share token and returns the link it belongs to if it has not expired or been revoked. it
+// does not count a download, see RecordDownload
+func (s ShareLinksController) Resolve(token string) (domain.ShareLink, error) {
+	payload, err := util.VerifyToken(s.secret, token)
+	if err != nil {
+		return domain.ShareLink{}, srverr.Wrap(domain.ErrShareLinkNotFound, http.StatusNotFound)
+	}
+
+	id, expiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return domain.ShareLink{}, srverr.Wrap(domain.ErrShareLinkNotFound, http.StatusNotFound)
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return domain.ShareLink{}, srverr.Wrap(domain.ErrShareLinkNotFound, http.StatusNotFound)
+	}
+
+	now := time.Now()
+	if now.Unix() >= expiresAt {
+		return domain.ShareLink{}, srverr.Wrap(domain.ErrShareLinkExpired, http.StatusGone)
+	}
+
+	// a used up link may still resume a download it already counted, RecordDownload rejects new ones
+	link, err := s.shareLinkRepo.GetShareLink(id)
+	if err == nil {
+		err = link.CheckUsable(now)
+	}
+	if err != nil && !errors.Is(err, domain.ErrShareLinkExhausted) {
+		return domain.ShareLink{}, shareLinkError(err)
+	}
+
+	return link, nil
+}
+
+// RecordDownload counts a download against the link, failing if the link was used up in the meantime
+func (s ShareLinksController) RecordDownload(link domain.ShareLink) (domain.ShareLink, error) {
+	link, err := s.shareLinkRepo.RecordDownload(link.Id, time.Now())
+	if err != nil {
+		return domain.ShareLink{}, shareLinkError(err)
+	}
+
+	return link, nil
+}
+
+func shareLinkError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrShareLinkNotFound):
+		return srverr.Wrap(err, http.StatusNotFound)
+	case errors.Is(err, domain.ErrShareLinkExpired), errors.Is(err, domain.ErrShareLinkRevoked), errors.Is(err, domain.ErrShareLinkExhausted):
+		return srverr.Wrap(err, http.StatusGone)
+	}
+	return err
+}
+
+func (s ShareLinksController) token(link domain.ShareLink) string {
+	return util.SignToken(s.secret, fmt.Sprintf("%v.%v", link.Id, link.ExpiresAt.Unix()))
+}
+
+var _ Controller = ShareLinksController{}
+var _ PrefixedRouteProvider = ShareLinksController{}
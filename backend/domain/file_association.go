@@ -2,6 +2,6 @@ package domain
 
 type FileAssociation struct {
 	Id            string `json:"id"`
-	FileExtension string `json:"fileExtension"`
-	BinaryPath    string `json:"binaryPath"`
+	FileExtension string `json:"fileExtension" validate:"required,max=32"`
+	BinaryPath    string `json:"binaryPath" validate:"required"`
 }
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrShareLinkNotFound  = errors.New("share link not found")
+	ErrShareLinkExpired   = errors.New("share link has expired")
+	ErrShareLinkRevoked   = errors.New("share link has been revoked")
+	ErrShareLinkExhausted = errors.New("share link has reached its download limit")
+)
+
+type ShareLink struct {
+	Id           string    `json:"id"`
+	Path         string    `json:"path"`
+	IsDirectory  bool      `json:"isDirectory"`
+	CreatedAt    time.Time `json:"createdAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	MaxDownloads int       `json:"maxDownloads"` // 0 means unlimited
+	Downloads    int       `json:"downloads"`
+	Revoked      bool      `json:"revoked"`
+}
+
+// CheckUsable returns an error describing why the link can no longer be downloaded, if any
+func (l ShareLink) CheckUsable(now time.Time) error {
+	if l.Revoked {
+		return ErrShareLinkRevoked
+	}
+
+	if !now.Before(l.ExpiresAt) {
+		return ErrShareLinkExpired
+	}
+
+	if l.MaxDownloads > 0 && l.Downloads >= l.MaxDownloads {
+		return ErrShareLinkExhausted
+	}
+
+	return nil
+}
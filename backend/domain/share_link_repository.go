@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+type ShareLinkRepository interface {
+	GetAllShareLinks() ([]ShareLink, error)
+	GetShareLink(id string) (ShareLink, error)
+	CreateShareLink(link ShareLink) (ShareLink, error)
+	RevokeShareLink(id string) error
+	// RecordDownload checks that the link is usable at the given time and counts a download against it
+	RecordDownload(id string, now time.Time) (ShareLink, error)
+}
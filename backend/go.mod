@@ -5,6 +5,7 @@ go 1.24.2
 require (
 	github.com/google/uuid v1.6.0
 	go.mongodb.org/mongo-driver/v2 v2.0.0
+	golang.org/x/sys v0.29.0
 )
 
 require (
@@ -1,29 +1,19 @@
 package main
 
 import (
-	"golang-web-core/srv"
-	"golang-web-core/srv/cfg"
-	"golang-web-core/util"
+	"fmt"
+	"golang-web-core/cli"
+	"golang-web-core/util/logging"
+	"log/slog"
+	"os"
 )
 
 func main() {
-	file := "default"
-	_, cfgFlag := cfg.GetArg("--config")
-	if cfgFlag != "" {
-		file = cfgFlag
-	}
-
-	cfg, err := cfg.FromFile(file)
-	if err != nil {
-		util.LogFatal(err)
-	}
-
-	srv, err := srv.NewServer(cfg)
+	_, err := logging.Setup(logging.Options{Level: slog.LevelInfo, Format: logging.FormatConsole})
 	if err != nil {
-		util.LogFatal(err)
+		fmt.Fprintf(os.Stderr, "could not set up logging: %v\n", err)
+		os.Exit(2)
 	}
 
-	if err := srv.Start(); err != nil {
-		util.LogFatal(err)
-	}
+	os.Exit(cli.Run(os.Args[1:]))
 }
@@ -0,0 +1,168 @@
+package fileassociationrepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-web-core/domain"
+	"golang-web-core/util/health"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// JsonConfig is the config of JsonFileAssociationRepository
+type JsonConfig struct {
+	Path string `json:"path"`
+}
+
+// Verify implements repositories.Verifier.
+func (c JsonConfig) Verify() error {
+	if c.Path == "" {
+		return errors.New("path is required")
+	}
+	if !filepath.IsAbs(c.Path) {
+		return fmt.Errorf("path must be absolute, got %v", c.Path)
+	}
+
+	info, err := os.Stat(filepath.Dir(c.Path))
+	if err != nil {
+		return fmt.Errorf("the directory of path does not exist: %v", filepath.Dir(c.Path))
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("the parent of path is not a directory: %v", filepath.Dir(c.Path))
+	}
+
+	info, err = os.Stat(c.Path)
+	if err == nil && info.IsDir() {
+		return fmt.Errorf("path is a directory: %v", c.Path)
+	}
+	return nil
+}
+
+// JsonFileAssociationRepository stores file associations in a json file
+type JsonFileAssociationRepository struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewJsonFileAssociationRepository(config JsonConfig) (*JsonFileAssociationRepository, error) {
+	err := config.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	return &JsonFileAssociationRepository{path: config.Path}, nil
+}
+
+func (j *JsonFileAssociationRepository) read() ([]domain.FileAssociation, error) {
+	bytes, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []domain.FileAssociation{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	associations := []domain.FileAssociation{}
+	err = json.Unmarshal(bytes, &associations)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %v: %v", j.path, err)
+	}
+	return associations, nil
+}
+
+// write replaces the file atomically so a crash never leaves it half written
+func (j *JsonFileAssociationRepository) write(associations []domain.FileAssociation) error {
+	bytes, err := json.MarshalIndent(associations, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(bytes)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), j.path)
+}
+
+// CreateAssociation implements domain.FileAssociationRepository.
+func (j *JsonFileAssociationRepository) CreateAssociation(association domain.FileAssociation) (domain.FileAssociation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	associations, err := j.read()
+	if err != nil {
+		return domain.FileAssociation{}, err
+	}
+
+	association.Id = uuid.New().String()
+	err = j.write(append(associations, association))
+	if err != nil {
+		return domain.FileAssociation{}, err
+	}
+
+	return association, nil
+}
+
+// DeleteAssociation implements domain.FileAssociationRepository.
+func (j *JsonFileAssociationRepository) DeleteAssociation(id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	associations, err := j.read()
+	if err != nil {
+		return err
+	}
+
+	return j.write(slices.DeleteFunc(associations, func(association domain.FileAssociation) bool {
+		return association.Id == id
+	}))
+}
+
+// GetAllAssociations implements domain.FileAssociationRepository.
+func (j *JsonFileAssociationRepository) GetAllAssociations() ([]domain.FileAssociation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.read()
+}
+
+// Check implements health.Checker.
+func (j *JsonFileAssociationRepository) Check(ctx context.Context) error {
+	// the file is replaced on every write, so it is the directory that has to be writable
+	err := health.Writable(filepath.Dir(j.path)).Check(ctx)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// a write may have held the lock until the check timed out
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err = j.read()
+	return err
+}
+
+var _ domain.FileAssociationRepository = &JsonFileAssociationRepository{}
+var _ health.Checker = &JsonFileAssociationRepository{}
@@ -0,0 +1,166 @@
+package fileassociationrepo
+
+import (
+	"context"
+	"errors"
+	"golang-web-core/domain"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestJsonConfigVerify(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name      string
+		path      string
+		expectErr bool
+	}{
+		{name: "New file", path: filepath.Join(dir, "associations.json")},
+		{name: "Missing path", path: "", expectErr: true},
+		{name: "Relative path", path: "associations.json", expectErr: true},
+		{name: "Missing directory", path: filepath.Join(dir, "missing", "associations.json"), expectErr: true},
+		{name: "Path is a directory", path: dir, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := JsonConfig{Path: tc.path}.Verify()
+			if tc.expectErr != (err != nil) {
+				t.Errorf("Expected error %v, but got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestJsonFileAssociationRepository(t *testing.T) {
+	testCases := []struct {
+		name      string
+		existing  string
+		create    []domain.FileAssociation
+		delete    []int
+		want      []domain.FileAssociation
+		expectErr bool
+	}{
+		{
+			name: "Missing file is empty",
+			want: []domain.FileAssociation{},
+		},
+		{
+			name:   "Create",
+			create: []domain.FileAssociation{{FileExtension: "txt", BinaryPath: "/usr/bin/vim"}, {FileExtension: "png", BinaryPath: "/usr/bin/feh"}},
+			want:   []domain.FileAssociation{{FileExtension: "txt", BinaryPath: "/usr/bin/vim"}, {FileExtension: "png", BinaryPath: "/usr/bin/feh"}},
+		},
+		{
+			name:   "Delete",
+			create: []domain.FileAssociation{{FileExtension: "txt", BinaryPath: "/usr/bin/vim"}, {FileExtension: "png", BinaryPath: "/usr/bin/feh"}},
+			delete: []int{0},
+			want:   []domain.FileAssociation{{FileExtension: "png", BinaryPath: "/usr/bin/feh"}},
+		},
+		{
+			name:     "Existing file",
+			existing: `[{"id":"1","fileExtension":"md","binaryPath":"/usr/bin/less"}]`,
+			create:   []domain.FileAssociation{{FileExtension: "txt", BinaryPath: "/usr/bin/vim"}},
+			want:     []domain.FileAssociation{{FileExtension: "md", BinaryPath: "/usr/bin/less"}, {FileExtension: "txt", BinaryPath: "/usr/bin/vim"}},
+		},
+		{
+			name:      "Invalid file",
+			existing:  `{"fileExtension":`,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "associations.json")
+			if tc.existing != "" {
+				err := os.WriteFile(path, []byte(tc.existing), 0o600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			repo, err := NewJsonFileAssociationRepository(JsonConfig{Path: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expectErr {
+				_, err := repo.GetAllAssociations()
+				if err == nil {
+					t.Errorf("Expected an error reading the file, but got nil")
+				}
+				if repo.Check(context.Background()) == nil {
+					t.Errorf("Expected the check to fail")
+				}
+				return
+			}
+
+			created := []domain.FileAssociation{}
+			for _, association := range tc.create {
+				association, err := repo.CreateAssociation(association)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if association.Id == "" {
+					t.Errorf("Expected the created association to get an id")
+				}
+				created = append(created, association)
+			}
+			for _, i := range tc.delete {
+				err := repo.DeleteAssociation(created[i].Id)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// a new repository reads what the first one wrote
+			reopened, err := NewJsonFileAssociationRepository(JsonConfig{Path: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			associations, err := reopened.GetAllAssociations()
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			for i := range associations {
+				associations[i].Id = ""
+			}
+			if !slices.Equal(associations, tc.want) {
+				t.Errorf("Expected %+v, but got %+v", tc.want, associations)
+			}
+
+			err = repo.Check(context.Background())
+			if err != nil {
+				t.Errorf("Expected the check to pass, but got: %v", err)
+			}
+
+			// writes replace the file, no temp files are left behind
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) > 1 {
+				t.Errorf("Expected only the associations file, but got %v entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestJsonCheckCancelled(t *testing.T) {
+	repo, err := NewJsonFileAssociationRepository(JsonConfig{Path: filepath.Join(t.TempDir(), "associations.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = repo.Check(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got: %v", err)
+	}
+}
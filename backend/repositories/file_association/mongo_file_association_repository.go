@@ -0,0 +1,173 @@
+package fileassociationrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-web-core/domain"
+	"golang-web-core/util/database_adapters/mongo"
+	"golang-web-core/util/health"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MongoConfig is the config of MongoFileAssociationRepository
+type MongoConfig struct {
+	Uri        string `json:"uri"`
+	Collection string `json:"collection,omitempty"`
+}
+
+// Verify implements repositories.Verifier.
+func (c MongoConfig) Verify() error {
+	_, err := c.adapterConfig()
+	return err
+}
+
+// Redacted implements repositories.Redacter.
+func (c MongoConfig) Redacted() any {
+	u, err := url.Parse(c.Uri)
+	if err == nil {
+		c.Uri = u.Redacted()
+	}
+	return c
+}
+
+func (c MongoConfig) collection() string {
+	if c.Collection == "" {
+		return "fileAssociations"
+	}
+	return c.Collection
+}
+
+// adapterConfig checks the uri and hands it to the adapter unchanged, so options like replicaSet or authSource
+// and mongodb+srv:// uris keep working
+func (c MongoConfig) adapterConfig() (mongo.Config, error) {
+	if c.Uri == "" {
+		return mongo.Config{}, errors.New("uri is required")
+	}
+
+	u, err := url.Parse(c.Uri)
+	if err != nil {
+		return mongo.Config{}, fmt.Errorf("uri is not a valid url: %v", errors.Unwrap(err))
+	}
+	if u.Scheme != "mongodb" && u.Scheme != "mongodb+srv" {
+		return mongo.Config{}, fmt.Errorf("uri must start with mongodb:// or mongodb+srv://, got %q", u.Scheme+"://")
+	}
+	if u.Host == "" {
+		return mongo.Config{}, errors.New("uri has no host")
+	}
+
+	database := strings.TrimPrefix(u.Path, "/")
+	if database == "" || strings.Contains(database, "/") {
+		return mongo.Config{}, errors.New("uri must name a database, e.g. mongodb://localhost:27017/lfe")
+	}
+
+	return mongo.Config{URI: c.Uri, Hostname: u.Host, Database: database}, nil
+}
+
+type mongoFileAssociation struct {
+	Id            string `bson:"_id"`
+	FileExtension string `bson:"fileExtension"`
+	BinaryPath    string `bson:"binaryPath"`
+}
+
+// MongoFileAssociationRepository stores file associations in a mongo collection. it keeps one client connected
+// until Close
+type MongoFileAssociationRepository struct {
+	adapter    *mongo.Mongo
+	collection string
+}
+
+func NewMongoFileAssociationRepository(config MongoConfig) (*MongoFileAssociationRepository, error) {
+	adapterConfig, err := config.adapterConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoFileAssociationRepository{
+		adapter:    mongo.NewMongoAdapter(adapterConfig, true),
+		collection: config.collection(),
+	}, nil
+}
+
+// CreateAssociation implements domain.FileAssociationRepository.
+func (m *MongoFileAssociationRepository) CreateAssociation(association domain.FileAssociation) (domain.FileAssociation, error) {
+	client, ctx, cancel, err := m.adapter.Shared(context.Background())
+	if err != nil {
+		return domain.FileAssociation{}, err
+	}
+	defer cancel()
+
+	association.Id = uuid.New().String()
+	_, err = m.adapter.InsertOne(client, ctx, m.collection, mongoFileAssociation(association))
+	if err != nil {
+		return domain.FileAssociation{}, err
+	}
+
+	return association, nil
+}
+
+// DeleteAssociation implements domain.FileAssociationRepository.
+func (m *MongoFileAssociationRepository) DeleteAssociation(id string) error {
+	client, ctx, cancel, err := m.adapter.Shared(context.Background())
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return m.adapter.DeleteOne(client, ctx, m.collection, bson.M{"_id": id})
+}
+
+// GetAllAssociations implements domain.FileAssociationRepository.
+func (m *MongoFileAssociationRepository) GetAllAssociations() ([]domain.FileAssociation, error) {
+	client, ctx, cancel, err := m.adapter.Shared(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	cursor, err := m.adapter.Query(client, ctx, m.collection, bson.M{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := []mongoFileAssociation{}
+	err = cursor.All(ctx, &documents)
+	if err != nil {
+		return nil, err
+	}
+
+	associations := make([]domain.FileAssociation, 0, len(documents))
+	for _, document := range documents {
+		associations = append(associations, domain.FileAssociation(document))
+	}
+	return associations, nil
+}
+
+// Check implements health.Checker.
+func (m *MongoFileAssociationRepository) Check(ctx context.Context) error {
+	client, ctx, cancel, err := m.adapter.Shared(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return m.adapter.Ping(client, ctx)
+}
+
+// Close implements io.Closer.
+func (m *MongoFileAssociationRepository) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return m.adapter.Disconnect(ctx)
+}
+
+var _ domain.FileAssociationRepository = &MongoFileAssociationRepository{}
+var _ health.Checker = &MongoFileAssociationRepository{}
+var _ io.Closer = &MongoFileAssociationRepository{}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Verifier is implemented by repository configs that need more checks than decoding
+type Verifier interface {
+	Verify() error
+}
+
+// Redacter is implemented by repository configs that contain secrets. the returned value is printed instead
+type Redacter interface {
+	Redacted() any
+}
+
+// Factory builds a repository of one type from the config in cfg.RepositoryConfig.Config
+type Factory[R any] struct {
+	decode func(raw json.RawMessage) (any, error)
+	build  func(config any) (R, error)
+}
+
+// NoConfig is the factory of repository types without settings
+func NoConfig[R any](build func() (R, error)) Factory[R] {
+	return Factory[R]{
+		decode: func(raw json.RawMessage) (any, error) {
+			trimmed := bytes.TrimSpace(raw)
+			if len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) && !bytes.Equal(trimmed, []byte("{}")) {
+				return nil, fmt.Errorf("this repository type has no settings")
+			}
+			return nil, nil
+		},
+		build: func(any) (R, error) {
+			return build()
+		},
+	}
+}
+
+// WithConfig is the factory of repository types configured with C. the config is decoded strictly and
+// verified if C implements Verifier
+func WithConfig[C, R any](build func(config C) (R, error)) Factory[R] {
+	return Factory[R]{
+		decode: func(raw json.RawMessage) (any, error) {
+			var config C
+			if len(bytes.TrimSpace(raw)) == 0 {
+				raw = json.RawMessage("{}")
+			}
+
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.DisallowUnknownFields()
+			err := decoder.Decode(&config)
+			if err != nil {
+				return nil, err
+			}
+
+			if verifier, ok := any(config).(Verifier); ok {
+				err = verifier.Verify()
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return config, nil
+		},
+		build: func(config any) (R, error) {
+			return build(config.(C))
+		},
+	}
+}
+
+// Registry maps the repository types that can be configured for one kind of repository to their factories
+type Registry[R any] struct {
+	name      string
+	factories map[string]Factory[R]
+}
+
+// NewRegistry creates a registry. name is the config key it belongs to, e.g. fileAssociationRepository
+func NewRegistry[R any](name string) *Registry[R] {
+	return &Registry[R]{name: name, factories: map[string]Factory[R]{}}
+}
+
+// Register adds a repository type
+func (r *Registry[R]) Register(typ string, factory Factory[R]) *Registry[R] {
+	if _, ok := r.factories[typ]; ok {
+		panic(fmt.Sprintf("%v type %v was registered twice", r.name, typ))
+	}
+	r.factories[typ] = factory
+	return r
+}
+
+// Types lists the registered types, sorted
+func (r *Registry[R]) Types() []string {
+	types := make([]string, 0, len(r.factories))
+	for typ := range r.factories {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (r *Registry[R]) decode(typ string, raw json.RawMessage) (Factory[R], any, error) {
+	factory, ok := r.factories[typ]
+	if !ok {
+		return Factory[R]{}, nil, fmt.Errorf("unknown %v.type %q, expected one of %v", r.name, typ, strings.Join(r.Types(), ", "))
+	}
+
+	config, err := factory.decode(raw)
+	if err != nil {
+		return Factory[R]{}, nil, fmt.Errorf("invalid %v.config for %v: %v", r.name, typ, err)
+	}
+
+	return factory, config, nil
+}
+
+// Verify checks that typ is registered and that raw is a valid config for it
+func (r *Registry[R]) Verify(typ string, raw json.RawMessage) error {
+	_, _, err := r.decode(typ, raw)
+	return err
+}
+
+// New builds a repository of type typ
+func (r *Registry[R]) New(typ string, raw json.RawMessage) (R, error) {
+	factory, config, err := r.decode(typ, raw)
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+
+	return factory.build(config)
+}
+
+// Describe returns the settings of a repository as json with secrets redacted, or "" if the type has no settings
+func (r *Registry[R]) Describe(typ string, raw json.RawMessage) (string, error) {
+	_, config, err := r.decode(typ, raw)
+	if err != nil || config == nil {
+		return "", err
+	}
+
+	if redacter, ok := config.(Redacter); ok {
+		config = redacter.Redacted()
+	}
+
+	bytes, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
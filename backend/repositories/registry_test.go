@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"golang-web-core/srv/cfg"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type testRepository struct {
+	name string
+}
+
+type testConfig struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// Verify implements Verifier.
+func (c testConfig) Verify() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// Redacted implements Redacter.
+func (c testConfig) Redacted() any {
+	if c.Secret != "" {
+		c.Secret = "xxxxx"
+	}
+	return c
+}
+
+// Helper function to build a registry with one type of each factory
+func testRegistry() *Registry[testRepository] {
+	return NewRegistry[testRepository]("testRepository").
+		Register("Plain", NoConfig(func() (testRepository, error) {
+			return testRepository{name: "plain"}, nil
+		})).
+		Register("Configured", WithConfig(func(config testConfig) (testRepository, error) {
+			return testRepository{name: config.Name}, nil
+		}))
+}
+
+func TestRegistry(t *testing.T) {
+	testCases := []struct {
+		name         string
+		typ          string
+		config       string
+		expectErr    string
+		wantName     string
+		wantDescribe string
+	}{
+		{
+			name:     "No config",
+			typ:      "Plain",
+			wantName: "plain",
+		},
+		{
+			name:     "No config accepts null and an empty object",
+			typ:      "Plain",
+			config:   " {} ",
+			wantName: "plain",
+		},
+		{
+			name:      "No config rejects settings",
+			typ:       "Plain",
+			config:    `{"name":"other"}`,
+			expectErr: "invalid testRepository.config for Plain: this repository type has no settings",
+		},
+		{
+			name:         "With config",
+			typ:          "Configured",
+			config:       `{"name":"notes"}`,
+			wantName:     "notes",
+			wantDescribe: `{"name":"notes"}`,
+		},
+		{
+			name:         "Secrets are redacted",
+			typ:          "Configured",
+			config:       `{"name":"notes","secret":"hunter2"}`,
+			wantName:     "notes",
+			wantDescribe: `{"name":"notes","secret":"xxxxx"}`,
+		},
+		{
+			name:      "Unknown settings are rejected",
+			typ:       "Configured",
+			config:    `{"name":"notes","colour":"red"}`,
+			expectErr: `unknown field "colour"`,
+		},
+		{
+			name:      "Config is verified",
+			typ:       "Configured",
+			expectErr: "invalid testRepository.config for Configured: name is required",
+		},
+		{
+			name:      "Unknown type",
+			typ:       "Missing",
+			expectErr: `unknown testRepository.type "Missing", expected one of Configured, Plain`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := testRegistry()
+			raw := json.RawMessage(tc.config)
+
+			verifyErr := registry.Verify(tc.typ, raw)
+			repo, newErr := registry.New(tc.typ, raw)
+			describe, describeErr := registry.Describe(tc.typ, raw)
+
+			if tc.expectErr != "" {
+				for _, err := range []error{verifyErr, newErr, describeErr} {
+					if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+						t.Errorf("Expected an error containing %q, but got: %v", tc.expectErr, err)
+					}
+				}
+				return
+			}
+
+			for _, err := range []error{verifyErr, newErr, describeErr} {
+				if err != nil {
+					t.Fatalf("Expected no error, but got: %v", err)
+				}
+			}
+			if repo.name != tc.wantName {
+				t.Errorf("Expected repository %v, but got %v", tc.wantName, repo.name)
+			}
+			if describe != tc.wantDescribe {
+				t.Errorf("Expected description %q, but got %q", tc.wantDescribe, describe)
+			}
+		})
+	}
+}
+
+func TestRegistryTypes(t *testing.T) {
+	types := testRegistry().Types()
+	if !slices.Equal(types, []string{"Configured", "Plain"}) {
+		t.Errorf("Expected the sorted types, but got %v", types)
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering a type twice to panic")
+		}
+	}()
+
+	testRegistry().Register("Plain", NoConfig(func() (testRepository, error) {
+		return testRepository{}, nil
+	}))
+}
+
+func TestVerify(t *testing.T) {
+	valid := cfg.Config{
+		AppRepository:             cfg.RepositoryConfig{Type: "MockAppRepository"},
+		FileAssociationRepository: cfg.RepositoryConfig{Type: "MockFileAssociationRepository"},
+		ShareLinkRepository:       cfg.RepositoryConfig{Type: "ImdbShareLinkRepository"},
+	}
+
+	testCases := []struct {
+		name      string
+		modify    func(c *cfg.Config)
+		expectErr bool
+	}{
+		{name: "Valid", modify: func(c *cfg.Config) {}},
+		{name: "Unknown app repository", modify: func(c *cfg.Config) { c.AppRepository.Type = "Missing" }, expectErr: true},
+		{
+			name:      "Invalid file association settings",
+			modify:    func(c *cfg.Config) { c.FileAssociationRepository.Config = json.RawMessage(`{"path":"/tmp/a.json"}`) },
+			expectErr: true,
+		},
+		{name: "Missing share link repository", modify: func(c *cfg.Config) { c.ShareLinkRepository.Type = "" }, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := valid
+			tc.modify(&config)
+
+			err := Verify(config)
+			if tc.expectErr != (err != nil) {
+				t.Errorf("Expected error %v, but got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
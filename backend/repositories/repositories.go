@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"golang-web-core/domain"
+	apprepo "golang-web-core/repositories/app"
+	fileassociationrepo "golang-web-core/repositories/file_association"
+	sharelinkrepo "golang-web-core/repositories/share_link"
+	"golang-web-core/srv/cfg"
+)
+
+// these are the repository types that can be configured. to add one, register its factory here
+
+var Apps = NewRegistry[domain.AppRepository]("appRepository").
+	Register("MockAppRepository", NoConfig(func() (domain.AppRepository, error) {
+		return apprepo.MockAppRepository{}, nil
+	}))
+
+var FileAssociations = NewRegistry[domain.FileAssociationRepository]("fileAssociationRepository").
+	Register("MockFileAssociationRepository", NoConfig(func() (domain.FileAssociationRepository, error) {
+		return fileassociationrepo.MockFileAssociationRepository{}, nil
+	})).
+	Register("JsonFileAssociationRepository", WithConfig(func(config fileassociationrepo.JsonConfig) (domain.FileAssociationRepository, error) {
+		return fileassociationrepo.NewJsonFileAssociationRepository(config)
+	})).
+	Register("MongoFileAssociationRepository", WithConfig(func(config fileassociationrepo.MongoConfig) (domain.FileAssociationRepository, error) {
+		return fileassociationrepo.NewMongoFileAssociationRepository(config)
+	}))
+
+var ShareLinks = NewRegistry[domain.ShareLinkRepository]("shareLinkRepository").
+	Register("ImdbShareLinkRepository", NoConfig(func() (domain.ShareLinkRepository, error) {
+		return sharelinkrepo.NewImdbShareLinkRepository(), nil
+	}))
+
+// Verify checks each repository type in config and its settings against the registries
+func Verify(config cfg.Config) error {
+	err := Apps.Verify(config.AppRepository.Type, config.AppRepository.Config)
+	if err != nil {
+		return err
+	}
+
+	err = FileAssociations.Verify(config.FileAssociationRepository.Type, config.FileAssociationRepository.Config)
+	if err != nil {
+		return err
+	}
+
+	return ShareLinks.Verify(config.ShareLinkRepository.Type, config.ShareLinkRepository.Config)
+}
@@ -0,0 +1,102 @@
+package sharelinkrepo
+
+import (
+	"golang-web-core/domain"
+	"golang-web-core/util/database_adapters/imdb"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const shareLinkModel = "ShareLink"
+
+type ImdbShareLinkRepository struct {
+	mu sync.Mutex
+	db *imdb.Imdb
+}
+
+func NewImdbShareLinkRepository() *ImdbShareLinkRepository {
+	return &ImdbShareLinkRepository{db: imdb.NewImdbAdapter()}
+}
+
+// GetAllShareLinks implements domain.ShareLinkRepository.
+func (r *ImdbShareLinkRepository) GetAllShareLinks() ([]domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	links := []domain.ShareLink{}
+	for _, item := range r.db.GetAll(shareLinkModel) {
+		links = append(links, item.(domain.ShareLink))
+	}
+
+	return links, nil
+}
+
+// GetShareLink implements domain.ShareLinkRepository.
+func (r *ImdbShareLinkRepository) GetShareLink(id string) (domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.find(id)
+}
+
+// CreateShareLink implements domain.ShareLinkRepository.
+func (r *ImdbShareLinkRepository) CreateShareLink(link domain.ShareLink) (domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link.Id = uuid.New().String()
+	r.db.Insert(shareLinkModel, link)
+
+	return link, nil
+}
+
+// RevokeShareLink implements domain.ShareLinkRepository.
+func (r *ImdbShareLinkRepository) RevokeShareLink(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	link.Revoked = true
+
+	return r.db.Update(shareLinkModel, "Id", id, link)
+}
+
+// RecordDownload implements domain.ShareLinkRepository.
+func (r *ImdbShareLinkRepository) RecordDownload(id string, now time.Time) (domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, err := r.find(id)
+	if err != nil {
+		return domain.ShareLink{}, err
+	}
+
+	err = link.CheckUsable(now)
+	if err != nil {
+		return domain.ShareLink{}, err
+	}
+	link.Downloads++
+
+	err = r.db.Update(shareLinkModel, "Id", id, link)
+	if err != nil {
+		return domain.ShareLink{}, err
+	}
+
+	return link, nil
+}
+
+func (r *ImdbShareLinkRepository) find(id string) (domain.ShareLink, error) {
+	item, err := r.db.Find(shareLinkModel, "Id", id)
+	if err != nil {
+		return domain.ShareLink{}, domain.ErrShareLinkNotFound
+	}
+
+	return item.(domain.ShareLink), nil
+}
+
+var _ domain.ShareLinkRepository = &ImdbShareLinkRepository{}
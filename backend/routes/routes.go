@@ -4,7 +4,10 @@ import (
 	"golang-web-core/controllers"
 	"golang-web-core/srv/cfg"
 	"golang-web-core/srv/route"
+	"log/slog"
 	"net/http"
+	"slices"
+	"sort"
 )
 
 type Router struct {
@@ -17,10 +20,14 @@ func NewRouter(c cfg.Config) Router {
 	}
 }
 
+// Routes collects the routes of every controller registered in the application controller. controllers
+// implementing controllers.PrefixedRouteProvider have their prefix applied to all of their patterns
 func (r Router) Routes(appController controllers.ApplicationController) []route.Route {
-	// this is where you define your routes. you can do this however you like so long as you populate
-	// all of the fields in each route. each field is necessary. if you have a lot of routes, you can split
-	// your routes up into multiple files, so long as they are all returned here
+	names := []string{}
+	for name := range appController.Controllers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
 	routes := []route.Route{
 		{
@@ -28,14 +35,33 @@ func (r Router) Routes(appController controllers.ApplicationController) []route.
 			Method:         http.MethodGet,
 			Handler:        appController.Favicon,
 			ControllerName: appController.Name(),
+			Summary:        "Get the favicon",
+			Status:         http.StatusOK,
 		},
 	}
 
-	appsController := appController.GetController("AppsController").(controllers.AppsController)
-	routes = append(routes, appsController.Routes()...)
+	for _, name := range names {
+		controller := appController.Controllers[name]
+
+		provider, ok := controller.(controllers.RouteProvider)
+		if !ok {
+			slog.Debug("controller does not provide any routes", "controller", name)
+			continue
+		}
+
+		controllerRoutes := slices.Clone(provider.Routes())
+		if prefixed, ok := provider.(controllers.PrefixedRouteProvider); ok {
+			controllerRoutes = route.Group(prefixed.RoutePrefix(), controllerRoutes...)
+		}
 
-	associationsController := appController.GetController("AssociationsController").(controllers.AssociationsController)
-	routes = append(routes, associationsController.Routes()...)
+		for i := range controllerRoutes {
+			if controllerRoutes[i].ControllerName == "" {
+				controllerRoutes[i].ControllerName = name
+			}
+		}
+
+		routes = append(routes, controllerRoutes...)
+	}
 
 	return routes
 }
@@ -0,0 +1,50 @@
+package files
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// List returns the entries of the folder at path, folders first. hidden entries are left out unless hidden is set
+func (s *Service) List(ctx context.Context, path string, hidden bool) ([]Entry, error) {
+	path, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dirEntries, err := s.sandbox.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !hidden && strings.HasPrefix(dirEntry.Name(), ".") {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			// removed since the folder was read
+			continue
+		}
+		entries = append(entries, newEntry(filepath.Join(path, dirEntry.Name()), info))
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		if a.IsDirectory != b.IsDirectory {
+			if a.IsDirectory {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return entries, nil
+}
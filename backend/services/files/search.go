@@ -0,0 +1,88 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultSearchLimit is the number of results returned when a search sets no limit
+const DefaultSearchLimit = 1000
+
+var errLimitReached = errors.New("search limit reached")
+
+// SearchQuery selects the files a search returns. a file has to match every field that is set
+type SearchQuery struct {
+	// Name is matched case insensitively against the file name. it is a glob like *.go if it contains any of
+	// *?[, otherwise a substring
+	Name string
+	// Tag is a tag the file must have
+	Tag string
+	// Limit is the maximum number of results, defaults to DefaultSearchLimit
+	Limit int
+}
+
+func (q SearchQuery) matchesName(name string) bool {
+	if q.Name == "" {
+		return true
+	}
+
+	pattern, name := strings.ToLower(q.Name), strings.ToLower(name)
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, _ := filepath.Match(pattern, name)
+		return matched
+	}
+	return strings.Contains(name, pattern)
+}
+
+// Search returns the files below path that match query. folders are searched but not returned
+func (s *Service) Search(ctx context.Context, path string, query SearchQuery) ([]Entry, error) {
+	path, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultSearchLimit
+	}
+
+	results := []Entry{}
+	collect := func(rel string, info fs.FileInfo) error {
+		results = append(results, newEntry(filepath.Join(path, rel), info))
+		if len(results) >= query.Limit {
+			return errLimitReached
+		}
+		return nil
+	}
+
+	if query.Tag == "" {
+		// matching by name only needs the file info, so the files don't have to be opened
+		err = s.sandbox.StatFiles(ctx, path, func(rel string, info fs.FileInfo) error {
+			if !query.matchesName(info.Name()) {
+				return nil
+			}
+			return collect(rel, info)
+		})
+	} else {
+		err = s.sandbox.WalkFiles(ctx, path, func(rel string, file *os.File, info fs.FileInfo) error {
+			if !query.matchesName(info.Name()) {
+				return nil
+			}
+
+			tags, err := readTags(file)
+			if err != nil || !slices.Contains(tags, query.Tag) {
+				return nil
+			}
+			return collect(rel, info)
+		})
+	}
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return nil, err
+	}
+
+	return results, nil
+}
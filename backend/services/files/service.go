@@ -0,0 +1,60 @@
+package files
+
+import (
+	"errors"
+	"golang-web-core/util/jobs"
+	"golang-web-core/util/sandbox"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrIntoItself   = errors.New("a folder can't be copied or moved into itself")
+	ErrInvalidTag   = errors.New("tags must not be empty or contain commas")
+	ErrNoTagSupport = errors.New("the file system does not support extended attributes")
+)
+
+// Service implements the file operations shared by the http controllers and the command line. every path goes
+// through the sandbox and long operations are registered with the job tracker so shutdown waits for them
+type Service struct {
+	sandbox  *sandbox.Sandbox
+	jobs     *jobs.Tracker
+	trashDir string
+}
+
+// New creates a service. trashDir is the freedesktop trash directory, defaults to $XDG_DATA_HOME/Trash
+func New(sb *sandbox.Sandbox, tracker *jobs.Tracker, trashDir string) *Service {
+	if trashDir == "" {
+		trashDir = DefaultTrashDir()
+	}
+	return &Service{sandbox: sb, jobs: tracker, trashDir: trashDir}
+}
+
+// Entry is a file or folder
+type Entry struct {
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	IsDirectory bool      `json:"isDirectory"`
+	IsSymlink   bool      `json:"isSymlink"`
+	Size        int64     `json:"size"`
+	Mode        string    `json:"mode"`
+	ModifiedAt  time.Time `json:"modifiedAt"`
+}
+
+func newEntry(path string, info fs.FileInfo) Entry {
+	return Entry{
+		Name:        info.Name(),
+		Path:        path,
+		IsDirectory: info.IsDir(),
+		IsSymlink:   info.Mode()&fs.ModeSymlink != 0,
+		Size:        info.Size(),
+		Mode:        info.Mode().String(),
+		ModifiedAt:  info.ModTime(),
+	}
+}
+
+// resolve cleans path and checks that it is inside the sandbox
+func (s *Service) resolve(path string) (string, error) {
+	return s.sandbox.Resolve(filepath.Clean(path))
+}
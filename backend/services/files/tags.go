@@ -0,0 +1,122 @@
+package files
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// tags are stored in the user.xdg.tags extended attribute as a comma separated list, which is what the
+// desktop file managers read and write as well
+const tagsAttr = "user.xdg.tags"
+
+// Tags returns the tags of the file or folder at path
+func (s *Service) Tags(path string) ([]string, error) {
+	path, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.sandbox.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readTags(file)
+}
+
+// AddTags adds tags to the file or folder at path and returns all of its tags
+func (s *Service) AddTags(path string, tags ...string) ([]string, error) {
+	return s.updateTags(path, func(current []string) []string {
+		for _, tag := range tags {
+			if !slices.Contains(current, tag) {
+				current = append(current, tag)
+			}
+		}
+		return current
+	}, tags)
+}
+
+// RemoveTags removes tags from the file or folder at path and returns the tags that are left
+func (s *Service) RemoveTags(path string, tags ...string) ([]string, error) {
+	return s.updateTags(path, func(current []string) []string {
+		return slices.DeleteFunc(current, func(tag string) bool {
+			return slices.Contains(tags, tag)
+		})
+	}, tags)
+}
+
+func (s *Service) updateTags(path string, update func([]string) []string, tags []string) ([]string, error) {
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" || strings.Contains(tag, ",") {
+			return nil, ErrInvalidTag
+		}
+	}
+
+	path, err := s.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.sandbox.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	current, err := readTags(file)
+	if err != nil {
+		return nil, err
+	}
+
+	next := update(current)
+	if len(next) == 0 {
+		err = unix.Fremovexattr(int(file.Fd()), tagsAttr)
+		if errors.Is(err, unix.ENODATA) {
+			err = nil
+		}
+	} else {
+		err = unix.Fsetxattr(int(file.Fd()), tagsAttr, []byte(strings.Join(next, ",")), 0)
+	}
+	if err != nil {
+		return nil, xattrError(path, err)
+	}
+
+	return next, nil
+}
+
+func readTags(file *os.File) ([]string, error) {
+	buf := make([]byte, 1024)
+	for {
+		n, err := unix.Fgetxattr(int(file.Fd()), tagsAttr, buf)
+		if errors.Is(err, unix.ENODATA) {
+			return []string{}, nil
+		}
+		if errors.Is(err, unix.ERANGE) {
+			buf = make([]byte, len(buf)*4)
+			continue
+		}
+		if err != nil {
+			return nil, xattrError(file.Name(), err)
+		}
+
+		tags := []string{}
+		for _, tag := range strings.Split(string(buf[:n]), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return tags, nil
+	}
+}
+
+func xattrError(path string, err error) error {
+	if errors.Is(err, unix.ENOTSUP) {
+		return &os.PathError{Op: "xattr", Path: path, Err: ErrNoTagSupport}
+	}
+	return &os.PathError{Op: "xattr", Path: path, Err: err}
+}
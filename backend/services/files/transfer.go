@@ -0,0 +1,254 @@
+package files
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"golang-web-core/util"
+	"golang-web-core/util/jobs"
+	"golang-web-core/util/metrics"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Transfer is the result of a copy or move. Files and Bytes count what was copied, so a move within one file
+// system reports neither
+type Transfer struct {
+	Destination string `json:"destination"`
+	Files       int    `json:"files"`
+	Bytes       int64  `json:"bytes"`
+}
+
+// Copy copies the file or folder at source. when destination is an existing folder the copy is put inside it,
+// otherwise it is created at destination. existing files are only replaced when overwrite is set, and only once
+// their copy is complete. the copy stops when ctx is cancelled or the server shuts down, and the file that was
+// being written is removed
+func (s *Service) Copy(ctx context.Context, source, destination string, overwrite bool) (Transfer, error) {
+	ctx, done, err := s.jobs.Start(ctx, jobs.KindCopy)
+	if err != nil {
+		return Transfer{}, err
+	}
+	defer done()
+
+	source, target, err := s.transferPaths(source, destination)
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	return s.copy(ctx, source, target, overwrite)
+}
+
+// Move moves the file or folder at source, see Copy for how destination is interpreted. across file systems
+// the files are copied next to destination first and renamed into place once everything was copied, so a
+// failed or cancelled move leaves destination as it was. the source is removed after that
+func (s *Service) Move(ctx context.Context, source, destination string, overwrite bool) (Transfer, error) {
+	ctx, done, err := s.jobs.Start(ctx, jobs.KindMove)
+	if err != nil {
+		return Transfer{}, err
+	}
+	defer done()
+
+	source, target, err := s.transferPaths(source, destination)
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	err = s.sandbox.Rename(source, target, overwrite)
+	if !errors.Is(err, unix.EXDEV) {
+		return Transfer{Destination: target}, err
+	}
+
+	return s.moveAcross(ctx, source, target, overwrite)
+}
+
+// moveAcross moves source to target on another file system by copying it next to target, renaming the copy into
+// place and removing source
+func (s *Service) moveAcross(ctx context.Context, source, target string, overwrite bool) (Transfer, error) {
+	err := s.checkTarget(target, overwrite)
+	if err != nil {
+		return Transfer{Destination: target}, err
+	}
+
+	partial := partialPath(target)
+	transfer, err := s.copy(ctx, source, partial, false)
+	transfer.Destination = target
+	if err == nil {
+		err = s.sandbox.Rename(partial, target, overwrite)
+	}
+	if err != nil {
+		s.sandbox.RemoveAll(partial)
+		return transfer, err
+	}
+
+	return transfer, s.sandbox.RemoveAll(source)
+}
+
+// transferPaths resolves the source and the path it is copied or moved to. whether the target is inside the
+// source is decided on the paths with their symlinks resolved, so a link to the source can't be used as the target
+func (s *Service) transferPaths(source, destination string) (string, string, error) {
+	source, err := s.resolve(source)
+	if err != nil {
+		return "", "", err
+	}
+
+	target, err := s.resolve(destination)
+	if err != nil {
+		return "", "", err
+	}
+
+	info, err := s.sandbox.Stat(target)
+	if err == nil && info.IsDir() {
+		target = filepath.Join(target, filepath.Base(source))
+	}
+
+	realSource, err := s.sandbox.RealPath(source)
+	if err != nil {
+		return "", "", err
+	}
+	realTarget, err := s.sandbox.RealPath(target)
+	if err != nil {
+		return "", "", err
+	}
+
+	if realTarget == realSource || strings.HasPrefix(realTarget, realSource+"/") {
+		return "", "", ErrIntoItself
+	}
+
+	return source, target, nil
+}
+
+// checkTarget fails with fs.ErrExist when target exists and may not be replaced, before anything is copied.
+// the rename that puts the copy in place checks again in case target was created in the meantime
+func (s *Service) checkTarget(target string, overwrite bool) error {
+	if overwrite {
+		return nil
+	}
+
+	_, err := s.sandbox.Stat(target)
+	if err == nil {
+		return &fs.PathError{Op: "copy", Path: target, Err: fs.ErrExist}
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// partialPath is the hidden name next to path that a copy is written to before it is renamed to path
+func partialPath(path string) string {
+	return filepath.Join(filepath.Dir(path), fmt.Sprintf(".%v.%v.partial", filepath.Base(path), rand.Text()[:8]))
+}
+
+func (s *Service) copy(ctx context.Context, source, target string, overwrite bool) (Transfer, error) {
+	transfer := Transfer{Destination: target}
+
+	info, err := s.sandbox.Stat(source)
+	if err != nil {
+		return transfer, err
+	}
+
+	if !info.IsDir() {
+		file, err := s.sandbox.Open(source)
+		if err != nil {
+			return transfer, err
+		}
+		defer file.Close()
+
+		return transfer, s.copyFile(ctx, file, info, target, overwrite, &transfer)
+	}
+
+	// folders are created so that they can be filled and get the mode of their source once everything was
+	// copied. folders that already existed keep their mode
+	created := []createdDir{}
+	err = s.copyDir(target, info.Mode(), overwrite, &created)
+	if err != nil {
+		return transfer, err
+	}
+
+	err = s.sandbox.Walk(ctx, source, func(rel string, file *os.File, info fs.FileInfo) error {
+		dst := filepath.Join(target, rel)
+		if info.IsDir() {
+			return s.copyDir(dst, info.Mode(), overwrite, &created)
+		}
+
+		return s.copyFile(ctx, file, info, dst, overwrite, &transfer)
+	})
+	if err != nil {
+		return transfer, err
+	}
+
+	// deepest first, a folder without write or search permission would keep the ones below it from being changed
+	for i := len(created) - 1; i >= 0; i-- {
+		err = s.sandbox.Chmod(created[i].path, created[i].mode)
+		if err != nil {
+			return transfer, err
+		}
+	}
+
+	return transfer, nil
+}
+
+// createdDir is a folder created by a copy and the mode it gets once the copy is complete
+type createdDir struct {
+	path string
+	mode fs.FileMode
+}
+
+// copyDir creates the folder target. an existing folder is only used when overwrite is set
+func (s *Service) copyDir(target string, mode fs.FileMode, overwrite bool, created *[]createdDir) error {
+	err := s.sandbox.Mkdir(target, 0o700)
+	if errors.Is(err, fs.ErrExist) && overwrite {
+		info, statErr := s.sandbox.Stat(target)
+		if statErr == nil && info.IsDir() {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	*created = append(*created, createdDir{path: target, mode: mode.Perm()})
+	return nil
+}
+
+// copyFile writes src to a partial file next to target and renames it over target once it is complete, so an
+// existing target is only replaced by a full copy and a failed copy only leaves the partial file to remove
+func (s *Service) copyFile(ctx context.Context, src *os.File, info fs.FileInfo, target string, overwrite bool, transfer *Transfer) error {
+	err := s.checkTarget(target, overwrite)
+	if err != nil {
+		return err
+	}
+
+	partial := partialPath(target)
+	dst, err := s.sandbox.OpenFile(partial, os.O_WRONLY|os.O_CREATE|os.O_EXCL|unix.O_NOFOLLOW, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	n, err := util.CopyContext(ctx, dst, src)
+	metrics.BytesCopied.Add(float64(n))
+	transfer.Bytes += n
+	if err == nil && overwrite {
+		// the old contents are gone after the rename, make sure the new ones are on disk first
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = s.sandbox.Rename(partial, target, overwrite)
+	}
+
+	if err != nil {
+		s.sandbox.RemoveAll(partial)
+		return err
+	}
+
+	transfer.Files++
+	return nil
+}
@@ -0,0 +1,335 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"golang-web-core/util/jobs"
+	"golang-web-core/util/sandbox"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Helper function to create a service whose only root is a temp dir, with the trash next to it
+func newTestService(t *testing.T) (*Service, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	err := os.Mkdir(root, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sb, err := sandbox.New([]string{root}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(sb, jobs.NewTracker(), filepath.Join(dir, "Trash")), root
+}
+
+// Helper function to create a tree. paths ending with a slash are folders and their value is their mode, the
+// value of a file is its content
+func writeTree(t *testing.T, root string, tree map[string]string) {
+	t.Helper()
+
+	for _, path := range sortedKeys(tree) {
+		full := filepath.Join(root, path)
+		var err error
+		if strings.HasSuffix(path, "/") {
+			err = os.MkdirAll(full, 0o755)
+		} else {
+			err = os.MkdirAll(filepath.Dir(full), 0o755)
+			if err == nil {
+				err = os.WriteFile(full, []byte(tree[path]), 0o644)
+			}
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// modes are set once everything exists, so folders without write permission can be filled first
+	for _, path := range sortedKeys(tree) {
+		if strings.HasSuffix(path, "/") && tree[path] != "" {
+			mode, err := strconv.ParseUint(tree[path], 8, 32)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.Chmod(filepath.Join(root, path), fs.FileMode(mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// Helper function to read a tree in the format of writeTree, with the mode of every folder and the target of
+// every symlink
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+
+	tree := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if entry.IsDir() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			tree[rel+"/"] = info.Mode().Perm().String()
+			return nil
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			tree[rel] = "-> " + target
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		tree[rel] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// sortedKeys returns the paths of a tree with every folder before its entries
+func sortedKeys(tree map[string]string) []string {
+	return slices.Sorted(maps.Keys(tree))
+}
+
+func TestTransfer(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tree        map[string]string
+		links       map[string]string
+		move        bool
+		source      string
+		destination string
+		overwrite   bool
+		expectErr   error
+		wantTree    map[string]string
+		wantFiles   int
+	}{
+		{
+			name:        "Copy a file into a folder",
+			tree:        map[string]string{"a.txt": "a", "backup/": ""},
+			source:      "a.txt",
+			destination: "backup",
+			wantTree:    map[string]string{"a.txt": "a", "backup/": "-rwxr-xr-x", "backup/a.txt": "a"},
+			wantFiles:   1,
+		},
+		{
+			name:        "Existing file is kept without overwrite",
+			tree:        map[string]string{"a.txt": "new", "backup/a.txt": "old"},
+			source:      "a.txt",
+			destination: "backup",
+			expectErr:   fs.ErrExist,
+			wantTree:    map[string]string{"a.txt": "new", "backup/": "-rwxr-xr-x", "backup/a.txt": "old"},
+		},
+		{
+			name:        "Existing file is replaced with overwrite",
+			tree:        map[string]string{"a.txt": "new", "backup/a.txt": "old"},
+			source:      "a.txt",
+			destination: "backup",
+			overwrite:   true,
+			wantTree:    map[string]string{"a.txt": "new", "backup/": "-rwxr-xr-x", "backup/a.txt": "new"},
+			wantFiles:   1,
+		},
+		{
+			name:        "Existing folder is kept without overwrite",
+			tree:        map[string]string{"src/a.txt": "new", "backup/src/b.txt": "old"},
+			source:      "src",
+			destination: "backup",
+			expectErr:   fs.ErrExist,
+			wantTree:    map[string]string{"src/": "-rwxr-xr-x", "src/a.txt": "new", "backup/": "-rwxr-xr-x", "backup/src/": "-rwxr-xr-x", "backup/src/b.txt": "old"},
+		},
+		{
+			name:        "Existing folder is merged with overwrite",
+			tree:        map[string]string{"src/a.txt": "new", "backup/src/": "0700", "backup/src/b.txt": "old"},
+			source:      "src",
+			destination: "backup",
+			overwrite:   true,
+			wantTree:    map[string]string{"src/": "-rwxr-xr-x", "src/a.txt": "new", "backup/": "-rwxr-xr-x", "backup/src/": "-rwx------", "backup/src/a.txt": "new", "backup/src/b.txt": "old"},
+			wantFiles:   1,
+		},
+		{
+			name:        "Copy keeps empty folders and folder modes",
+			tree:        map[string]string{"src/": "0750", "src/empty/": "", "src/private/": "0700", "src/private/key": "k"},
+			source:      "src",
+			destination: "copy",
+			wantTree: map[string]string{
+				"src/": "-rwxr-x---", "src/empty/": "-rwxr-xr-x", "src/private/": "-rwx------", "src/private/key": "k",
+				"copy/": "-rwxr-x---", "copy/empty/": "-rwxr-xr-x", "copy/private/": "-rwx------", "copy/private/key": "k",
+			},
+			wantFiles: 1,
+		},
+		{
+			name:        "Copy a folder into itself",
+			tree:        map[string]string{"src/a.txt": "a"},
+			source:      "src",
+			destination: "src",
+			expectErr:   ErrIntoItself,
+			wantTree:    map[string]string{"src/": "-rwxr-xr-x", "src/a.txt": "a"},
+		},
+		{
+			name:        "Copy a folder into itself through a symlink",
+			tree:        map[string]string{"src/a.txt": "a"},
+			links:       map[string]string{"alias": "src"},
+			source:      "src",
+			destination: "alias",
+			expectErr:   ErrIntoItself,
+			wantTree:    map[string]string{"src/": "-rwxr-xr-x", "src/a.txt": "a", "alias": "-> src"},
+		},
+		{
+			name:        "Move a folder",
+			tree:        map[string]string{"src/a.txt": "a", "backup/": ""},
+			move:        true,
+			source:      "src",
+			destination: "backup",
+			wantTree:    map[string]string{"backup/": "-rwxr-xr-x", "backup/src/": "-rwxr-xr-x", "backup/src/a.txt": "a"},
+		},
+		{
+			name:        "Move a folder into itself",
+			tree:        map[string]string{"src/sub/": ""},
+			move:        true,
+			source:      "src",
+			destination: "src/sub",
+			expectErr:   ErrIntoItself,
+			wantTree:    map[string]string{"src/": "-rwxr-xr-x", "src/sub/": "-rwxr-xr-x"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, root := newTestService(t)
+			writeTree(t, root, tc.tree)
+			for link, target := range tc.links {
+				err := os.Symlink(target, filepath.Join(root, link))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fn := s.Copy
+			if tc.move {
+				fn = s.Move
+			}
+			transfer, err := fn(context.Background(), filepath.Join(root, tc.source), filepath.Join(root, tc.destination), tc.overwrite)
+
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Errorf("Expected %v, but got: %v", tc.expectErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			} else if transfer.Files != tc.wantFiles {
+				t.Errorf("Expected %v files to be copied, but got %v", tc.wantFiles, transfer.Files)
+			}
+
+			tree := readTree(t, root)
+			if !maps.Equal(tree, tc.wantTree) {
+				t.Errorf("Tree mismatch:\ngot  %v\nwant %v", tree, tc.wantTree)
+			}
+		})
+	}
+}
+
+func TestFailedTransferLeavesDestination(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tree     map[string]string
+		transfer func(s *Service, ctx context.Context, root string) error
+		wantTree map[string]string
+	}{
+		{
+			name: "Copy over an existing file",
+			tree: map[string]string{"a.txt": "new", "backup/a.txt": "old"},
+			transfer: func(s *Service, ctx context.Context, root string) error {
+				_, err := s.copy(ctx, filepath.Join(root, "a.txt"), filepath.Join(root, "backup", "a.txt"), true)
+				return err
+			},
+			wantTree: map[string]string{"a.txt": "new", "backup/": "-rwxr-xr-x", "backup/a.txt": "old"},
+		},
+		{
+			name: "Move across file systems",
+			tree: map[string]string{"src/a.txt": "a", "src/sub/b.txt": "b", "backup/src/c.txt": "old"},
+			transfer: func(s *Service, ctx context.Context, root string) error {
+				_, err := s.moveAcross(ctx, filepath.Join(root, "src"), filepath.Join(root, "backup", "src"), true)
+				return err
+			},
+			wantTree: map[string]string{
+				"src/": "-rwxr-xr-x", "src/a.txt": "a", "src/sub/": "-rwxr-xr-x", "src/sub/b.txt": "b",
+				"backup/": "-rwxr-xr-x", "backup/src/": "-rwxr-xr-x", "backup/src/c.txt": "old",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, root := newTestService(t)
+			writeTree(t, root, tc.tree)
+
+			// the copy fails once it has started, the partial files have to be removed again
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := tc.transfer(s, ctx, root)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, but got: %v", err)
+			}
+
+			tree := readTree(t, root)
+			if !maps.Equal(tree, tc.wantTree) {
+				t.Errorf("Tree mismatch:\ngot  %v\nwant %v", tree, tc.wantTree)
+			}
+		})
+	}
+}
+
+func TestMoveAcross(t *testing.T) {
+	s, root := newTestService(t)
+	writeTree(t, root, map[string]string{
+		"src/":              "0750",
+		"src/a.txt":         "a",
+		"src/empty/":        "",
+		"src/private/":      "0700",
+		"src/private/key":   "k",
+		"src/private/deep/": "0500",
+		"backup/":           "",
+	})
+
+	transfer, err := s.moveAcross(context.Background(), filepath.Join(root, "src"), filepath.Join(root, "backup", "src"), false)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if transfer.Files != 2 || transfer.Bytes != 2 {
+		t.Errorf("Expected 2 files and 2 bytes to be copied, but got %v and %v", transfer.Files, transfer.Bytes)
+	}
+
+	want := map[string]string{
+		"backup/":                  "-rwxr-xr-x",
+		"backup/src/":              "-rwxr-x---",
+		"backup/src/a.txt":         "a",
+		"backup/src/empty/":        "-rwxr-xr-x",
+		"backup/src/private/":      "-rwx------",
+		"backup/src/private/key":   "k",
+		"backup/src/private/deep/": "-r-x------",
+	}
+	tree := readTree(t, root)
+	if !maps.Equal(tree, want) {
+		t.Errorf("Tree mismatch:\ngot  %v\nwant %v", tree, want)
+	}
+}
@@ -0,0 +1,258 @@
+package files
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// the trash follows the freedesktop trash specification, so items trashed here show up in the trash of the
+// desktop and the other way around. the info files record the date in local time without a zone
+const trashDateFormat = "2006-01-02T15:04:05"
+
+// TrashItem is a file or folder in the trash
+type TrashItem struct {
+	Name         string    `json:"name"`
+	OriginalPath string    `json:"originalPath"`
+	DeletedAt    time.Time `json:"deletedAt"`
+}
+
+// DefaultTrashDir is the home trash, $XDG_DATA_HOME/Trash or ~/.local/share/Trash
+func DefaultTrashDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "Trash")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "Trash")
+}
+
+// openTrash opens the files and info folders of the trash, creating them if needed
+func (s *Service) openTrash() (*os.File, *os.File, error) {
+	if s.trashDir == "" {
+		return nil, nil, errors.New("the trash folder could not be determined, set XDG_DATA_HOME")
+	}
+
+	dirs := []*os.File{}
+	for _, name := range []string{"files", "info"} {
+		path := filepath.Join(s.trashDir, name)
+		err := os.MkdirAll(path, 0o700)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dir, err := os.OpenFile(path, os.O_RDONLY|unix.O_DIRECTORY, 0)
+		if err != nil {
+			for _, d := range dirs {
+				d.Close()
+			}
+			return nil, nil, err
+		}
+		dirs = append(dirs, dir)
+	}
+
+	return dirs[0], dirs[1], nil
+}
+
+// Trash moves the file or folder at path to the trash. the trash has to be on the same file system as path
+func (s *Service) Trash(ctx context.Context, path string) (TrashItem, error) {
+	path, err := s.resolve(path)
+	if err != nil {
+		return TrashItem{}, err
+	}
+
+	parent, name, err := s.sandbox.OpenParent(path)
+	if err != nil {
+		return TrashItem{}, err
+	}
+	defer parent.Close()
+
+	filesDir, infoDir, err := s.openTrash()
+	if err != nil {
+		return TrashItem{}, err
+	}
+	defer filesDir.Close()
+	defer infoDir.Close()
+
+	item := TrashItem{OriginalPath: path, DeletedAt: time.Now().Truncate(time.Second)}
+
+	// creating the info file with O_EXCL reserves the name, so two items with the same name can't collide
+	for i := 1; ; i++ {
+		if err := ctx.Err(); err != nil {
+			return TrashItem{}, err
+		}
+
+		item.Name = name
+		if i > 1 {
+			item.Name = fmt.Sprintf("%v.%v", name, i)
+		}
+
+		err = writeTrashInfo(infoDir, item)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return TrashItem{}, err
+		}
+
+		err = unix.Renameat2(int(parent.Fd()), name, int(filesDir.Fd()), item.Name, unix.RENAME_NOREPLACE)
+		if errors.Is(err, unix.EEXIST) {
+			unix.Unlinkat(int(infoDir.Fd()), item.Name+".trashinfo", 0)
+			continue
+		}
+		if err != nil {
+			unix.Unlinkat(int(infoDir.Fd()), item.Name+".trashinfo", 0)
+			if errors.Is(err, unix.EXDEV) {
+				return TrashItem{}, fmt.Errorf("%v is not on the same file system as the trash in %v: %w", path, s.trashDir, err)
+			}
+			return TrashItem{}, &os.LinkError{Op: "trash", Old: path, New: filepath.Join(s.trashDir, "files", item.Name), Err: err}
+		}
+
+		return item, nil
+	}
+}
+
+// ListTrash returns the items in the trash, most recently deleted first
+func (s *Service) ListTrash(ctx context.Context) ([]TrashItem, error) {
+	filesDir, infoDir, err := s.openTrash()
+	if err != nil {
+		return nil, err
+	}
+	defer filesDir.Close()
+	defer infoDir.Close()
+
+	names, err := infoDir.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []TrashItem{}
+	for _, infoName := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		name, ok := strings.CutSuffix(infoName, ".trashinfo")
+		if !ok {
+			continue
+		}
+
+		item, err := readTrashInfo(infoDir, name)
+		if err != nil {
+			// written by something else, not ours to fix
+			continue
+		}
+		items = append(items, item)
+	}
+
+	slices.SortFunc(items, func(a, b TrashItem) int {
+		return b.DeletedAt.Compare(a.DeletedAt)
+	})
+
+	return items, nil
+}
+
+// Restore moves an item from the trash back to where it was deleted from. it fails with fs.ErrExist when
+// something was created there in the meantime
+func (s *Service) Restore(ctx context.Context, name string) (TrashItem, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return TrashItem{}, &fs.PathError{Op: "restore", Path: name, Err: fs.ErrNotExist}
+	}
+
+	filesDir, infoDir, err := s.openTrash()
+	if err != nil {
+		return TrashItem{}, err
+	}
+	defer filesDir.Close()
+	defer infoDir.Close()
+
+	item, err := readTrashInfo(infoDir, name)
+	if err != nil {
+		return TrashItem{}, err
+	}
+
+	parent, originalName, err := s.sandbox.OpenParent(item.OriginalPath)
+	if err != nil {
+		return TrashItem{}, err
+	}
+	defer parent.Close()
+
+	err = unix.Renameat2(int(filesDir.Fd()), name, int(parent.Fd()), originalName, unix.RENAME_NOREPLACE)
+	if err != nil {
+		return TrashItem{}, &os.LinkError{Op: "restore", Old: filepath.Join(s.trashDir, "files", name), New: item.OriginalPath, Err: err}
+	}
+
+	err = unix.Unlinkat(int(infoDir.Fd()), name+".trashinfo", 0)
+	if err != nil {
+		return TrashItem{}, &fs.PathError{Op: "remove", Path: filepath.Join(s.trashDir, "info", name+".trashinfo"), Err: err}
+	}
+
+	return item, nil
+}
+
+func writeTrashInfo(infoDir *os.File, item TrashItem) error {
+	fd, err := unix.Openat(int(infoDir.Fd()), item.Name+".trashinfo", unix.O_WRONLY|unix.O_CREAT|unix.O_EXCL|unix.O_CLOEXEC, 0o600)
+	if err != nil {
+		return &fs.PathError{Op: "open", Path: item.Name + ".trashinfo", Err: err}
+	}
+	file := os.NewFile(uintptr(fd), item.Name+".trashinfo")
+
+	// the path is url encoded like the specification asks for
+	original := (&url.URL{Path: item.OriginalPath}).EscapedPath()
+	_, err = fmt.Fprintf(file, "[Trash Info]\nPath=%v\nDeletionDate=%v\n", original, item.DeletedAt.Format(trashDateFormat))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		unix.Unlinkat(int(infoDir.Fd()), item.Name+".trashinfo", 0)
+	}
+	return err
+}
+
+func readTrashInfo(infoDir *os.File, name string) (TrashItem, error) {
+	fd, err := unix.Openat(int(infoDir.Fd()), name+".trashinfo", unix.O_RDONLY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return TrashItem{}, &fs.PathError{Op: "open", Path: name + ".trashinfo", Err: err}
+	}
+	file := os.NewFile(uintptr(fd), name+".trashinfo")
+	defer file.Close()
+
+	item := TrashItem{Name: name}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		switch key {
+		case "Path":
+			item.OriginalPath, err = url.PathUnescape(value)
+			if err != nil {
+				return TrashItem{}, err
+			}
+		case "DeletionDate":
+			item.DeletedAt, err = time.ParseInLocation(trashDateFormat, value, time.Local)
+			if err != nil {
+				return TrashItem{}, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return TrashItem{}, err
+	}
+
+	if !filepath.IsAbs(item.OriginalPath) {
+		return TrashItem{}, fmt.Errorf("%v.trashinfo has no absolute Path", name)
+	}
+	return item, nil
+}
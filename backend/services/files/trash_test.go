@@ -0,0 +1,150 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	testCases := []struct {
+		name      string
+		tree      map[string]string
+		trash     []string
+		wantNames []string
+		restore   string
+		recreate  bool
+		expectErr error
+		wantTree  map[string]string
+	}{
+		{
+			name:      "File",
+			tree:      map[string]string{"notes.txt": "n"},
+			trash:     []string{"notes.txt"},
+			wantNames: []string{"notes.txt"},
+			restore:   "notes.txt",
+			wantTree:  map[string]string{"notes.txt": "n"},
+		},
+		{
+			name:      "Folder with a name that needs escaping",
+			tree:      map[string]string{"my photos/a.jpg": "a"},
+			trash:     []string{"my photos"},
+			wantNames: []string{"my photos"},
+			restore:   "my photos",
+			wantTree:  map[string]string{"my photos/": "-rwxr-xr-x", "my photos/a.jpg": "a"},
+		},
+		{
+			name:      "Same name twice",
+			tree:      map[string]string{"a/notes.txt": "a", "b/notes.txt": "b"},
+			trash:     []string{"a/notes.txt", "b/notes.txt"},
+			wantNames: []string{"notes.txt", "notes.txt.2"},
+			restore:   "notes.txt.2",
+			wantTree:  map[string]string{"a/": "-rwxr-xr-x", "b/": "-rwxr-xr-x", "b/notes.txt": "b"},
+		},
+		{
+			name:      "Original path was taken in the meantime",
+			tree:      map[string]string{"notes.txt": "old"},
+			trash:     []string{"notes.txt"},
+			wantNames: []string{"notes.txt"},
+			restore:   "notes.txt",
+			recreate:  true,
+			expectErr: fs.ErrExist,
+			wantTree:  map[string]string{"notes.txt": "new"},
+		},
+		{
+			name:      "Unknown item",
+			tree:      map[string]string{"notes.txt": "n"},
+			trash:     []string{"notes.txt"},
+			wantNames: []string{"notes.txt"},
+			restore:   "../notes.txt",
+			expectErr: fs.ErrNotExist,
+			wantTree:  map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, root := newTestService(t)
+			writeTree(t, root, tc.tree)
+
+			for i, path := range tc.trash {
+				item, err := s.Trash(context.Background(), filepath.Join(root, path))
+				if err != nil {
+					t.Fatalf("Expected no error trashing %v, but got: %v", path, err)
+				}
+				if item.Name != tc.wantNames[i] {
+					t.Errorf("Expected the name %v, but got %v", tc.wantNames[i], item.Name)
+				}
+
+				// the info file follows the freedesktop trash specification
+				info, err := os.ReadFile(filepath.Join(s.trashDir, "info", item.Name+".trashinfo"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := fmt.Sprintf("[Trash Info]\nPath=%v\nDeletionDate=%v\n", escapedPath(filepath.Join(root, path)), item.DeletedAt.Format("2006-01-02T15:04:05"))
+				if string(info) != want {
+					t.Errorf("Info file mismatch:\ngot  %q\nwant %q", info, want)
+				}
+				if time.Since(item.DeletedAt) > time.Minute {
+					t.Errorf("Expected the deletion date to be now, but got %v", item.DeletedAt)
+				}
+
+				_, err = os.Lstat(filepath.Join(s.trashDir, "files", item.Name))
+				if err != nil {
+					t.Errorf("Expected the item to be in the trash, but got: %v", err)
+				}
+			}
+
+			items, err := s.ListTrash(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != len(tc.trash) {
+				t.Errorf("Expected %v items in the trash, but got %+v", len(tc.trash), items)
+			}
+
+			if tc.recreate {
+				writeTree(t, root, map[string]string{tc.trash[0]: "new"})
+			}
+
+			item, err := s.Restore(context.Background(), tc.restore)
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Errorf("Expected %v, but got: %v", tc.expectErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			} else {
+				_, err = os.Stat(filepath.Join(s.trashDir, "info", item.Name+".trashinfo"))
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("Expected the info file to be removed, but got: %v", err)
+				}
+			}
+
+			tree := readTree(t, root)
+			if !maps.Equal(tree, tc.wantTree) {
+				t.Errorf("Tree mismatch:\ngot  %v\nwant %v", tree, tc.wantTree)
+			}
+		})
+	}
+}
+
+// escapedPath url encodes path like the info files do, every byte except unreserved characters and slashes
+func escapedPath(path string) string {
+	escaped := ""
+	for _, b := range []byte(path) {
+		switch {
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9', b == '/', b == '-', b == '_', b == '.', b == '~':
+			escaped += string(b)
+		default:
+			escaped += fmt.Sprintf("%%%02X", b)
+		}
+	}
+	return escaped
+}
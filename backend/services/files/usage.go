@@ -0,0 +1,75 @@
+package files
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/sys/unix"
+)
+
+// Usage is how much space the files below a folder take up
+type Usage struct {
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+	// Largest are the biggest files, largest first
+	Largest []Entry `json:"largest"`
+	// DiskSize and DiskAvailable describe the file system the folder is on
+	DiskSize      uint64 `json:"diskSize"`
+	DiskAvailable uint64 `json:"diskAvailable"`
+}
+
+// Usage adds up the size of the files below path and keeps the top largest of them
+func (s *Service) Usage(ctx context.Context, path string, top int) (Usage, error) {
+	path, err := s.resolve(path)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	usage := Usage{Path: path, Largest: []Entry{}}
+
+	dir, err := s.sandbox.Open(path)
+	if err != nil {
+		return Usage{}, err
+	}
+	var stat unix.Statfs_t
+	err = unix.Fstatfs(int(dir.Fd()), &stat)
+	dir.Close()
+	if err != nil {
+		return Usage{}, &fs.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	usage.DiskSize = stat.Blocks * uint64(stat.Bsize)
+	usage.DiskAvailable = stat.Bavail * uint64(stat.Bsize)
+
+	err = s.sandbox.StatFiles(ctx, path, func(rel string, info fs.FileInfo) error {
+		usage.Files++
+		usage.Bytes += info.Size()
+
+		if top <= 0 || (len(usage.Largest) == top && info.Size() <= usage.Largest[top-1].Size) {
+			return nil
+		}
+
+		i, _ := slices.BinarySearchFunc(usage.Largest, info.Size(), func(e Entry, size int64) int {
+			// sorted descending
+			switch {
+			case e.Size > size:
+				return -1
+			case e.Size < size:
+				return 1
+			}
+			return 0
+		})
+		usage.Largest = slices.Insert(usage.Largest, i, newEntry(filepath.Join(path, rel), info))
+		if len(usage.Largest) > top {
+			usage.Largest = usage.Largest[:top]
+		}
+		return nil
+	})
+	if err != nil {
+		return Usage{}, err
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+const (
+	// ScopeAll is granted to the main api token
+	ScopeAll     = "*"
+	ScopeRead    = "read"
+	ScopeWrite   = "write"
+	ScopeMetrics = "metrics"
+)
+
+// Token is an api token and the scopes it grants
+type Token struct {
+	Name   string
+	Value  string
+	Scopes []string
+}
+
+// Principal is the caller a request was authenticated as
+type Principal struct {
+	Name   string
+	Scopes []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, ScopeAll) || slices.Contains(p.Scopes, scope)
+}
+
+type principalKeyType string
+
+const principalKey principalKeyType = "principal"
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid bearer token")
+)
+
+func DefaultTokenPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "linux-file-explorer", "token"), nil
+}
+
+// LoadToken reads the api token from path. it refuses files other users can access
+func LoadToken(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("the token file %v is accessible by other users, please run chmod 600 on it", path)
+	}
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(bytes))
+	if token == "" {
+		return "", fmt.Errorf("the token file %v is empty", path)
+	}
+
+	return token, nil
+}
+
+// LoadOrCreateToken reads the api token from path, generating it with 0600 permissions on first start. the token
+// is written to a temporary file that is linked into place, so path never exists without a token in it and when
+// two servers start at once both end up with the token of whichever linked it first
+func LoadOrCreateToken(path string) (string, error) {
+	token, err := LoadToken(path)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return token, err
+	}
+
+	dir := filepath.Dir(path)
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	bytes := make([]byte, 32)
+	_, err = rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	token = hex.EncodeToString(bytes)
+
+	// CreateTemp creates the file with 0600 permissions
+	file, err := os.CreateTemp(dir, ".token-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(token + "\n")
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err != nil {
+		return "", err
+	}
+	if closeErr != nil {
+		return "", closeErr
+	}
+
+	// unlike a rename, a link fails instead of replacing a token another server created in the meantime
+	err = os.Link(file.Name(), path)
+	if errors.Is(err, fs.ErrExist) {
+		return LoadToken(path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Authenticate finds the token the request carries as "Authorization: Bearer <token>"
+func Authenticate(req *http.Request, tokens []Token) (Principal, error) {
+	header := req.Header.Get("Authorization")
+	if header == "" {
+		return Principal{}, ErrMissingToken
+	}
+
+	scheme, value, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Principal{}, ErrMissingToken
+	}
+	value = strings.TrimSpace(value)
+
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(token.Value)) == 1 {
+			return Principal{Name: token.Name, Scopes: token.Scopes}, nil
+		}
+	}
+
+	return Principal{}, ErrInvalidToken
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestLoadOrCreateToken(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contents    string
+		perm        os.FileMode
+		wantToken   string
+		expectError bool
+	}{
+		{
+			name:      "Creates a missing token",
+			wantToken: "",
+		},
+		{
+			name:      "Reads an existing token",
+			contents:  "existing-token\n",
+			perm:      0o600,
+			wantToken: "existing-token",
+		},
+		{
+			name:        "Rejects a token other users can read",
+			contents:    "existing-token\n",
+			perm:        0o644,
+			expectError: true,
+		},
+		{
+			name:        "Rejects an empty token file",
+			contents:    "",
+			perm:        0o600,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config", "token")
+			if tc.perm != 0 {
+				err := os.MkdirAll(filepath.Dir(path), 0o700)
+				if err != nil {
+					t.Fatalf("Failed to create the config dir: %v", err)
+				}
+				err = os.WriteFile(path, []byte(tc.contents), tc.perm)
+				if err != nil {
+					t.Fatalf("Failed to write the token file: %v", err)
+				}
+				err = os.Chmod(path, tc.perm)
+				if err != nil {
+					t.Fatalf("Failed to set the token file permissions: %v", err)
+				}
+			}
+
+			token, err := LoadOrCreateToken(path)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, but got token %q", token)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if tc.wantToken != "" && token != tc.wantToken {
+				t.Errorf("Token mismatch: got %q, want %q", token, tc.wantToken)
+			}
+			if tc.wantToken == "" && len(token) != 64 {
+				t.Errorf("Expected a generated 64 character token, but got %q", token)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Expected the token file to exist, but got: %v", err)
+			}
+			if info.Mode().Perm() != 0o600 {
+				t.Errorf("Expected the token file to have 0600 permissions, but got %v", info.Mode().Perm())
+			}
+
+			loaded, err := LoadToken(path)
+			if err != nil || loaded != token {
+				t.Errorf("Expected LoadToken to return %q, but got %q, %v", token, loaded, err)
+			}
+
+			entries, _ := os.ReadDir(filepath.Dir(path))
+			if len(entries) != 1 {
+				t.Errorf("Expected only the token file in the config dir, but found %v entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestLoadOrCreateTokenConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+
+	tokens := make([]string, 8)
+	errs := make([]error, len(tokens))
+	var wg sync.WaitGroup
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[i], errs[i] = LoadOrCreateToken(path)
+		}()
+	}
+	wg.Wait()
+
+	for i := range tokens {
+		if errs[i] != nil {
+			t.Fatalf("Expected no error, but got: %v", errs[i])
+		}
+		if tokens[i] != tokens[0] {
+			t.Errorf("Expected every server to end up with the same token, but got %q and %q", tokens[0], tokens[i])
+		}
+	}
+}
+
+func TestLoadTokenMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+
+	_, err := LoadToken(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, but got: %v", err)
+	}
+
+	_, err = os.Stat(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected LoadToken not to create the token file, but got: %v", err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	tokens := []Token{
+		{Name: "main", Value: "main-token", Scopes: []string{ScopeAll}},
+		{Name: "reader", Value: "reader-token", Scopes: []string{ScopeRead}},
+	}
+
+	testCases := []struct {
+		name       string
+		header     string
+		wantName   string
+		wantScopes []string
+		wantErr    error
+	}{
+		{
+			name:       "Main token",
+			header:     "Bearer main-token",
+			wantName:   "main",
+			wantScopes: []string{ScopeAll},
+		},
+		{
+			name:       "Scoped token with a lowercase scheme",
+			header:     "bearer reader-token",
+			wantName:   "reader",
+			wantScopes: []string{ScopeRead},
+		},
+		{
+			name:    "Missing header",
+			header:  "",
+			wantErr: ErrMissingToken,
+		},
+		{
+			name:    "Basic auth",
+			header:  "Basic bWFpbi10b2tlbg==",
+			wantErr: ErrMissingToken,
+		},
+		{
+			name:    "Unknown token",
+			header:  "Bearer other-token",
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "Empty token",
+			header:  "Bearer ",
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/files", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			principal, err := Authenticate(req, tokens)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Expected error %v, but got: %v", tc.wantErr, err)
+			}
+			if principal.Name != tc.wantName || !slices.Equal(principal.Scopes, tc.wantScopes) {
+				t.Errorf("Principal mismatch: got %+v, want %v %v", principal, tc.wantName, tc.wantScopes)
+			}
+		})
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	testCases := []struct {
+		name      string
+		scopes    []string
+		scope     string
+		wantScope bool
+	}{
+		{name: "All scopes", scopes: []string{ScopeAll}, scope: ScopeWrite, wantScope: true},
+		{name: "Granted scope", scopes: []string{ScopeRead, ScopeWrite}, scope: ScopeWrite, wantScope: true},
+		{name: "Missing scope", scopes: []string{ScopeRead}, scope: ScopeWrite, wantScope: false},
+		{name: "No scopes", scopes: nil, scope: ScopeRead, wantScope: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal := Principal{Name: "test", Scopes: tc.scopes}
+			if got := principal.HasScope(tc.scope); got != tc.wantScope {
+				t.Errorf("Expected HasScope(%v) to be %v, but got %v", tc.scope, tc.wantScope, got)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package cfg
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"golang-web-core/util/logging"
 	"os"
+	"time"
 )
 
 type Environment string
@@ -18,19 +21,172 @@ type RepositoryConfig struct {
 	Config json.RawMessage `json:"config"`
 }
 
+// UnmarshalJSON layers a repository config on top of the current one like the rest of the config, except that the
+// settings of the previous type are dropped when the type changes, since they belong to another repository
+func (r *RepositoryConfig) UnmarshalJSON(data []byte) error {
+	// layer has the same fields without this method
+	type layer RepositoryConfig
+	var next layer
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&next)
+	if err != nil {
+		return err
+	}
+
+	if next.Type != "" && next.Type != r.Type {
+		r.Type = next.Type
+		r.Config = nil
+	}
+	if next.Config != nil {
+		r.Config = next.Config
+	}
+	return nil
+}
+
 type Config struct {
 	Port                      int              `json:"port"`
+	DisableTCP                bool             `json:"disableTcp"`
+	UnixSocket                UnixSocket       `json:"unixSocket"`
 	SSL                       SSL              `json:"ssl"`
 	PublicFS                  bool             `json:"enablePublicFS"`
 	Env                       Environment      `json:"env"`
 	AppRepository             RepositoryConfig `json:"appRepository"`
 	FileAssociationRepository RepositoryConfig `json:"fileAssociationRepository"`
+	ShareLinkRepository       RepositoryConfig `json:"shareLinkRepository"`
+	ShareLinks                ShareLinks       `json:"shareLinks"`
+	// Hosts are the addresses the tcp listener binds to. they default to 127.0.0.1 and ::1 so the server is only
+	// reachable from this machine, use 0.0.0.0 or :: to expose it to the network
+	Hosts []string `json:"hosts"`
+	// AllowedRoots are the only directories clients may access. defaults to the user's home directory
+	AllowedRoots []string `json:"allowedRoots"`
+	// DeniedPaths are files and directories inside of the allowed roots that clients may never access
+	DeniedPaths []string `json:"deniedPaths"`
+	// AuthTokenPath is where the api token is stored. defaults to $XDG_CONFIG_HOME/linux-file-explorer/token
+	AuthTokenPath string `json:"authTokenPath"`
+	// AuthTokens are extra api tokens that are limited to some scopes, e.g. a read only token for a dashboard
+	AuthTokens []ScopedToken `json:"authTokens"`
+	// ReadOnly rejects every request that would modify files or settings
+	ReadOnly bool     `json:"readOnly"`
+	Limits   Limits   `json:"limits"`
+	Timeouts Timeouts `json:"timeouts"`
+	CORS     CORS     `json:"cors"`
+	Logging  Logging  `json:"logging"`
 }
 
 func (c Config) IsSSL() bool {
 	return c.SSL.CertPath != "" && c.SSL.KeyPath != ""
 }
 
+type Logging struct {
+	// Level is one of debug, info, warn or error
+	Level string `json:"level"`
+	// Format is either console or json
+	Format string `json:"format"`
+	// File is where logs are written to instead of stderr. it is rotated once it reaches MaxSizeMB
+	File       string `json:"file"`
+	MaxSizeMB  int    `json:"maxSizeMb"`
+	MaxBackups int    `json:"maxBackups"`
+	// SlowRequestMs is how long a request may take before it is logged as slow. 0 disables the warning
+	SlowRequestMs int `json:"slowRequestMs"`
+}
+
+func (l Logging) SlowRequestThreshold() time.Duration {
+	return time.Duration(l.SlowRequestMs) * time.Millisecond
+}
+
+func (l Logging) Options() logging.Options {
+	level, _ := logging.ParseLevel(l.Level)
+
+	return logging.Options{
+		Level:      level,
+		Format:     l.Format,
+		File:       l.File,
+		MaxSizeMB:  l.MaxSizeMB,
+		MaxBackups: l.MaxBackups,
+	}
+}
+
+type ScopedToken struct {
+	Name string `json:"name"`
+	// Path is where the token is stored, it is generated on first start like the main token
+	Path string `json:"path"`
+	// Scopes are any of read, write and metrics
+	Scopes []string `json:"scopes"`
+}
+
+type Limits struct {
+	// MaxBodyMB is the largest request body accepted by routes that modify data
+	MaxBodyMB int `json:"maxBodyMb"`
+	// RequestsPerSecond and Burst limit how often a client may call routes that modify data. 0 disables the limit
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+func (l Limits) MaxBodyBytes() int64 {
+	return int64(l.MaxBodyMB) << 20
+}
+
+// Timeouts are in seconds, 0 means no timeout
+type Timeouts struct {
+	// ReadHeaderSeconds is how long a client may take to send the request headers
+	ReadHeaderSeconds int `json:"readHeaderSeconds"`
+	// ReadSeconds is how long a client may take to send the whole request, including uploads
+	ReadSeconds int `json:"readSeconds"`
+	// WriteSeconds is how long a response may take. share downloads are streamed and are not limited by it
+	WriteSeconds int `json:"writeSeconds"`
+	// IdleSeconds is how long keep-alive connections are kept open between requests
+	IdleSeconds int `json:"idleSeconds"`
+	// ShutdownSeconds is how long requests and jobs get to finish after SIGINT or SIGTERM before they are cancelled
+	ShutdownSeconds int `json:"shutdownSeconds"`
+}
+
+func (t Timeouts) ReadHeader() time.Duration {
+	return time.Duration(t.ReadHeaderSeconds) * time.Second
+}
+
+func (t Timeouts) Read() time.Duration {
+	return time.Duration(t.ReadSeconds) * time.Second
+}
+
+func (t Timeouts) Write() time.Duration {
+	return time.Duration(t.WriteSeconds) * time.Second
+}
+
+func (t Timeouts) Idle() time.Duration {
+	return time.Duration(t.IdleSeconds) * time.Second
+}
+
+func (t Timeouts) Shutdown() time.Duration {
+	return time.Duration(t.ShutdownSeconds) * time.Second
+}
+
+type CORS struct {
+	// AllowedOrigins lists the browser origins that may call the api, "*" allows any origin.
+	// requests without an Origin header (like the desktop app) are not affected
+	AllowedOrigins []string `json:"allowedOrigins"`
+	AllowedMethods []string `json:"allowedMethods"`
+	AllowedHeaders []string `json:"allowedHeaders"`
+	MaxAgeSeconds  int      `json:"maxAgeSeconds"`
+}
+
+type UnixSocket struct {
+	Enabled bool `json:"enabled"`
+	// Path defaults to $XDG_RUNTIME_DIR/linux-file-explorer.sock
+	Path string `json:"path"`
+}
+
+type ShareLinks struct {
+	// Secret is the key used to sign share tokens. if it is left empty, a random one is generated on startup
+	// and every share link becomes invalid when the server restarts
+	Secret            string `json:"secret"`
+	DefaultTTLSeconds int    `json:"defaultTtlSeconds"`
+	MaxTTLSeconds     int    `json:"maxTtlSeconds"`
+
+	generatedSecret bool
+}
+
 type SSL struct {
 	CertPath string `json:"certPath"`
 	KeyPath  string `json:"keyPath"`
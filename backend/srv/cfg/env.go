@@ -0,0 +1,216 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const envPrefix = "LFE_"
+
+// EnvName returns the environment variable that overrides the setting at a json path, e.g. logging.level
+// becomes LFE_LOGGING_LEVEL and unixSocket.path becomes LFE_UNIX_SOCKET_PATH
+func EnvName(path string) string {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		parts[i] = screamingSnake(part)
+	}
+	return envPrefix + strings.Join(parts, "_")
+}
+
+func screamingSnake(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+
+	return b.String()
+}
+
+type envField struct {
+	path  string
+	value reflect.Value
+}
+
+// envFields lists every setting that can be overridden by an environment variable, keyed by the variable name
+func envFields(value reflect.Value, prefix string, fields map[string]envField) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		path := prefix + name
+
+		if field.Type.Kind() == reflect.Struct {
+			envFields(value.Field(i), path+".", fields)
+			continue
+		}
+
+		fields[EnvName(path)] = envField{path: path, value: value.Field(i)}
+	}
+}
+
+// EnvNames lists the environment variables that override settings, sorted
+func EnvNames() []string {
+	fields := map[string]envField{}
+	envFields(reflect.ValueOf(&Config{}).Elem(), "", fields)
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyEnv sets the settings overridden by LFE_* variables in environ. lists are comma separated, lists of
+// objects and repository configs are json. unknown LFE_* variables are logged so typos don't go unnoticed, but
+// they don't stop the server since other tools may share the prefix
+func applyEnv(config *Config, environ []string) error {
+	fields := map[string]envField{}
+	envFields(reflect.ValueOf(config).Elem(), "", fields)
+
+	before := *config
+	set := map[string]bool{}
+	defer dropStaleRepositoryConfigs(before, config, set)
+
+	for _, entry := range environ {
+		name, raw, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, envPrefix) || name == ConfigEnv {
+			continue
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			slog.Warn("ignoring unknown environment variable", "name", name)
+			continue
+		}
+
+		err := setFromEnv(field.value, raw)
+		if err != nil {
+			return fmt.Errorf("%v (%v): %v", name, field.path, err)
+		}
+		set[field.path] = true
+	}
+
+	return nil
+}
+
+// apply sets the overridden settings, parsed the same way as environment variables
+func (o Overrides) apply(config *Config) error {
+	fields := map[string]envField{}
+	envFields(reflect.ValueOf(config).Elem(), "", fields)
+
+	paths := make([]string, 0, len(o))
+	for path := range o {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	before := *config
+	set := map[string]bool{}
+	defer dropStaleRepositoryConfigs(before, config, set)
+
+	for _, path := range paths {
+		field, ok := fields[EnvName(path)]
+		if !ok {
+			return fmt.Errorf("unknown setting %v", path)
+		}
+
+		err := setFromEnv(field.value, o[path])
+		if err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+		set[field.path] = true
+	}
+
+	return nil
+}
+
+// dropStaleRepositoryConfigs clears the config of every repository whose type was changed without setting its
+// config as well, like RepositoryConfig.UnmarshalJSON does for config files. set holds the paths that were set
+func dropStaleRepositoryConfigs(before Config, config *Config, set map[string]bool) {
+	old, next := reflect.ValueOf(before), reflect.ValueOf(config).Elem()
+
+	for i := 0; i < next.NumField(); i++ {
+		if next.Field(i).Type() != reflect.TypeOf(RepositoryConfig{}) {
+			continue
+		}
+
+		repo := next.Field(i).Addr().Interface().(*RepositoryConfig)
+		name, _, _ := strings.Cut(next.Type().Field(i).Tag.Get("json"), ",")
+		if repo.Type != old.Field(i).Interface().(RepositoryConfig).Type && !set[name+".config"] {
+			repo.Config = nil
+		}
+	}
+}
+
+func setFromEnv(value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		value.SetInt(i)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.String {
+			items := []string{}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			value.Set(reflect.ValueOf(items).Convert(value.Type()))
+			return nil
+		}
+		fallthrough
+	default:
+		// json.RawMessage and lists of objects
+		if value.Type() == reflect.TypeOf(json.RawMessage{}) && !json.Valid([]byte(raw)) {
+			return fmt.Errorf("expected json")
+		}
+		err := json.Unmarshal([]byte(raw), value.Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("expected json: %v", err)
+		}
+	}
+
+	return nil
+}
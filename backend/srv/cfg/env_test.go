@@ -0,0 +1,141 @@
+package cfg
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "port", expected: "LFE_PORT"},
+		{path: "logging.level", expected: "LFE_LOGGING_LEVEL"},
+		{path: "unixSocket.path", expected: "LFE_UNIX_SOCKET_PATH"},
+		{path: "enablePublicFS", expected: "LFE_ENABLE_PUBLIC_FS"},
+		{path: "limits.maxBodyMb", expected: "LFE_LIMITS_MAX_BODY_MB"},
+		{path: "shareLinks.defaultTtlSeconds", expected: "LFE_SHARE_LINKS_DEFAULT_TTL_SECONDS"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			name := EnvName(tc.path)
+			if name != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, name)
+			}
+			if !slices.Contains(EnvNames(), name) {
+				t.Errorf("%v is not listed by EnvNames", name)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	testCases := []struct {
+		name      string
+		environ   []string
+		expectErr bool
+		check     func(t *testing.T, config Config)
+	}{
+		{
+			name:    "Strings, Numbers and Booleans",
+			environ: []string{"LFE_LOGGING_LEVEL=warn", "LFE_PORT=4000", "LFE_READ_ONLY=true", "LFE_LIMITS_REQUESTS_PER_SECOND=2.5"},
+			check: func(t *testing.T, config Config) {
+				if config.Logging.Level != "warn" || config.Port != 4000 || !config.ReadOnly || config.Limits.RequestsPerSecond != 2.5 {
+					t.Errorf("the settings were not applied: %+v", config)
+				}
+			},
+		},
+		{
+			name:    "Comma Separated Lists",
+			environ: []string{"LFE_ALLOWED_ROOTS=/srv/a, /srv/b,"},
+			check: func(t *testing.T, config Config) {
+				if !slices.Equal(config.AllowedRoots, []string{"/srv/a", "/srv/b"}) {
+					t.Errorf("expected [/srv/a /srv/b], got %v", config.AllowedRoots)
+				}
+			},
+		},
+		{
+			name:    "Lists of Objects",
+			environ: []string{`LFE_AUTH_TOKENS=[{"name":"dashboard","path":"/tmp/dashboard","scopes":["read"]}]`},
+			check: func(t *testing.T, config Config) {
+				if len(config.AuthTokens) != 1 || config.AuthTokens[0].Name != "dashboard" {
+					t.Errorf("expected the dashboard token, got %+v", config.AuthTokens)
+				}
+			},
+		},
+		{
+			name:    "Other Variables Are Ignored",
+			environ: []string{"PORT=5000", "LFE_CONFIG=testing", "LFE_NOT_A_SETTING=1", "LFE_PORT=4000"},
+			check: func(t *testing.T, config Config) {
+				if config.Port != 4000 {
+					t.Errorf("expected port 4000, got %v", config.Port)
+				}
+			},
+		},
+		{
+			name:    "Repository Type Change Drops the Old Config",
+			environ: []string{"LFE_FILE_ASSOCIATION_REPOSITORY_TYPE=MockFileAssociationRepository"},
+			check: func(t *testing.T, config Config) {
+				if config.FileAssociationRepository.Type != "MockFileAssociationRepository" || config.FileAssociationRepository.Config != nil {
+					t.Errorf("expected the mock repository without settings, got %+v", config.FileAssociationRepository)
+				}
+			},
+		},
+		{
+			name:    "Repository Type Change With Its Config",
+			environ: []string{"LFE_FILE_ASSOCIATION_REPOSITORY_TYPE=OtherRepository", `LFE_FILE_ASSOCIATION_REPOSITORY_CONFIG={"path":"/tmp/b.json"}`},
+			check: func(t *testing.T, config Config) {
+				if string(config.FileAssociationRepository.Config) != `{"path":"/tmp/b.json"}` {
+					t.Errorf("expected the new settings, got %s", config.FileAssociationRepository.Config)
+				}
+			},
+		},
+		{
+			name:    "Same Repository Type Keeps the Config",
+			environ: []string{"LFE_FILE_ASSOCIATION_REPOSITORY_TYPE=JsonFileAssociationRepository"},
+			check: func(t *testing.T, config Config) {
+				if string(config.FileAssociationRepository.Config) != `{"path":"/tmp/a.json"}` {
+					t.Errorf("expected the settings to be kept, got %s", config.FileAssociationRepository.Config)
+				}
+			},
+		},
+		{
+			name:      "Invalid Boolean",
+			environ:   []string{"LFE_READ_ONLY=sometimes"},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid Number",
+			environ:   []string{"LFE_PORT=http"},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid Json",
+			environ:   []string{"LFE_AUTH_TOKENS=dashboard"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := Config{
+				Port:                      3000,
+				FileAssociationRepository: RepositoryConfig{Type: "JsonFileAssociationRepository", Config: json.RawMessage(`{"path":"/tmp/a.json"}`)},
+			}
+			err := applyEnv(&config, tc.environ)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tc.check(t, config)
+		})
+	}
+}
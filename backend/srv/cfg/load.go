@@ -0,0 +1,136 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// AppName is used for the config and runtime directories
+const AppName = "linux-file-explorer"
+
+// ConfigEnv names the environment variable that can point to the config file instead of --config
+const ConfigEnv = "LFE_CONFIG"
+
+// SearchPaths lists where Load looks for the config file when none is given, in order
+func SearchPaths() []string {
+	paths := []string{}
+
+	dir, err := os.UserConfigDir()
+	if err == nil {
+		paths = append(paths, filepath.Join(dir, AppName, "config.json"))
+	}
+
+	return append(paths, filepath.Join("/etc", AppName, "config.json"), filepath.Join("configs", "default.json"))
+}
+
+// Overrides are settings given on the command line, keyed by their json path, e.g. logging.level
+type Overrides map[string]string
+
+// Load reads the config. the first path is the base config, without paths $LFE_CONFIG or the first file of
+// SearchPaths that exists is used. the *.json files in a config.d directory next to the base config are layered
+// on top of it in name order, followed by the remaining paths and the LFE_* environment variables. the result is
+// verified and returned with the files it was read from
+func Load(paths ...string) (Config, []string, error) {
+	return Overrides(nil).Load(paths...)
+}
+
+// Load is like Load but applies the overrides last, so they win over the files and the environment
+func (o Overrides) Load(paths ...string) (Config, []string, error) {
+	if len(paths) == 0 {
+		base, err := findConfig()
+		if err != nil {
+			return Config{}, nil, err
+		}
+		paths = []string{base}
+	}
+
+	paths[0] = resolvePath(paths[0])
+	overrides, err := filepath.Glob(filepath.Join(filepath.Dir(paths[0]), "config.d", "*.json"))
+	if err != nil {
+		return Config{}, nil, err
+	}
+	sort.Strings(overrides)
+	paths = slices.Concat(paths[:1], overrides, paths[1:])
+
+	config := Config{}
+	files := []string{}
+	for _, path := range paths {
+		path = resolvePath(path)
+
+		err := decodeFile(path, &config)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		files = append(files, path)
+	}
+
+	err = applyEnv(&config, os.Environ())
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	err = o.apply(&config)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	err = config.Verify()
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	return config, files, nil
+}
+
+func findConfig() (string, error) {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return resolvePath(path), nil
+	}
+
+	searched := SearchPaths()
+	for _, path := range searched {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("no config file found, looked in %v", strings.Join(searched, ", "))
+}
+
+// resolvePath keeps supporting plain config names like "default", which refer to configs/default.json
+func resolvePath(path string) string {
+	if !strings.ContainsRune(path, filepath.Separator) && !strings.HasSuffix(path, ".json") {
+		return filepath.Join("configs", path+".json")
+	}
+	return path
+}
+
+// decodeFile layers the settings in path on top of config. keys that don't exist are rejected
+func decodeFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(config)
+	if err != nil {
+		return fmt.Errorf("%v: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,288 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "default", expected: filepath.Join("configs", "default.json")},
+		{path: "testing", expected: filepath.Join("configs", "testing.json")},
+		{path: "local.json", expected: "local.json"},
+		{path: "./local", expected: "./local"},
+		{path: "/etc/linux-file-explorer/config.json", expected: "/etc/linux-file-explorer/config.json"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			path := resolvePath(tc.path)
+			if path != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, path)
+			}
+		})
+	}
+}
+
+func TestSearchPaths(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/test/.config")
+
+	expected := []string{
+		"/home/test/.config/linux-file-explorer/config.json",
+		"/etc/linux-file-explorer/config.json",
+		filepath.Join("configs", "default.json"),
+	}
+
+	paths := SearchPaths()
+	if !slices.Equal(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	if _, err := os.Stat("/etc/linux-file-explorer/config.json"); err == nil {
+		t.Skip("a system wide config is installed")
+	}
+
+	testCases := []struct {
+		name       string
+		configEnv  string
+		userConfig bool
+		repoConfig bool
+		expected   string
+		expectUser bool
+		expectErr  bool
+	}{
+		{
+			name:       "Config Environment Variable",
+			configEnv:  "testing",
+			userConfig: true,
+			repoConfig: true,
+			expected:   filepath.Join("configs", "testing.json"),
+		},
+		{
+			name:       "User Config Before the Repository Config",
+			userConfig: true,
+			repoConfig: true,
+			expectUser: true,
+		},
+		{
+			name:       "Repository Config",
+			repoConfig: true,
+			expected:   filepath.Join("configs", "default.json"),
+		},
+		{
+			name:      "No Config",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "user"))
+			t.Setenv(ConfigEnv, tc.configEnv)
+
+			if tc.userConfig {
+				writeConfig(t, filepath.Join(dir, "user", AppName, "config.json"), "{}")
+			}
+			if tc.repoConfig {
+				writeConfig(t, filepath.Join("configs", "default.json"), "{}")
+			}
+
+			path, err := findConfig()
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := tc.expected
+			if tc.expectUser {
+				expected = filepath.Join(dir, "user", AppName, "config.json")
+			}
+			if path != expected {
+				t.Errorf("expected %v, got %v", expected, path)
+			}
+		})
+	}
+}
+
+func TestLoadLayering(t *testing.T) {
+	base, err := os.ReadFile(filepath.Join("..", "..", "configs", "default.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name      string
+		environ   map[string]string
+		overrides Overrides
+		port      int
+		level     string
+	}{
+		{
+			name:  "Files",
+			port:  3003,
+			level: "warn",
+		},
+		{
+			name:    "Environment Over Files",
+			environ: map[string]string{"LFE_PORT": "3004", "LFE_NOT_A_SETTING": "1"},
+			port:    3004,
+			level:   "warn",
+		},
+		{
+			name:      "Overrides Over the Environment",
+			environ:   map[string]string{"LFE_PORT": "3004", "LFE_LOGGING_LEVEL": "error"},
+			overrides: Overrides{"port": "3005"},
+			port:      3005,
+			level:     "error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("HOME", dir)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+			t.Setenv("LFE_ALLOWED_ROOTS", dir)
+			for name, value := range tc.environ {
+				t.Setenv(name, value)
+			}
+
+			writeConfig(t, filepath.Join(dir, "base.json"), string(base))
+			// config.d files are applied in name order, whatever order they were written in
+			writeConfig(t, filepath.Join(dir, "config.d", "20-level.json"), `{"port": 3002, "logging": {"level": "warn"}}`)
+			writeConfig(t, filepath.Join(dir, "config.d", "10-limits.json"), `{"port": 3001, "limits": {"maxBodyMb": 20}}`)
+			writeConfig(t, filepath.Join(dir, "config.d", "notes.txt"), "not a config")
+			writeConfig(t, filepath.Join(dir, "extra.json"), `{"port": 3003}`)
+
+			config, files, err := tc.overrides.Load(filepath.Join(dir, "base.json"), filepath.Join(dir, "extra.json"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expectedFiles := []string{
+				filepath.Join(dir, "base.json"),
+				filepath.Join(dir, "config.d", "10-limits.json"),
+				filepath.Join(dir, "config.d", "20-level.json"),
+				filepath.Join(dir, "extra.json"),
+			}
+			if !slices.Equal(files, expectedFiles) {
+				t.Errorf("expected files %v, got %v", expectedFiles, files)
+			}
+
+			if config.Port != tc.port {
+				t.Errorf("expected port %v, got %v", tc.port, config.Port)
+			}
+			if config.Logging.Level != tc.level {
+				t.Errorf("expected level %v, got %v", tc.level, config.Logging.Level)
+			}
+			// settings that no later layer touches are kept
+			if config.Limits.MaxBodyMB != 20 || config.Logging.Format != "console" {
+				t.Errorf("earlier layers were lost: %+v %+v", config.Limits, config.Logging)
+			}
+			if !slices.Equal(config.AllowedRoots, []string{dir}) {
+				t.Errorf("expected allowed roots [%v], got %v", dir, config.AllowedRoots)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, filepath.Join(dir, "base.json"), `{"prot": 3000}`)
+
+	_, _, err := Load(filepath.Join(dir, "base.json"))
+	if err == nil {
+		t.Fatal("expected an error for an unknown key")
+	}
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRepositoryLayers(t *testing.T) {
+	base, err := os.ReadFile(filepath.Join("..", "..", "configs", "default.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		layer      string
+		overrides  Overrides
+		wantType   string
+		wantConfig string
+	}{
+		{
+			name:       "Config Is Layered Like Other Settings",
+			layer:      `{"fileAssociationRepository": {"config": {"path": "/tmp/b.json"}}}`,
+			wantType:   "JsonFileAssociationRepository",
+			wantConfig: `{"path": "/tmp/b.json"}`,
+		},
+		{
+			name:     "Type Change Drops the Old Config",
+			layer:    `{"fileAssociationRepository": {"type": "MockFileAssociationRepository"}}`,
+			wantType: "MockFileAssociationRepository",
+		},
+		{
+			name:       "Type Change With Its Own Config",
+			layer:      `{"fileAssociationRepository": {"type": "JsonFileAssociationRepository", "config": {"path": "/tmp/b.json"}}}`,
+			wantType:   "JsonFileAssociationRepository",
+			wantConfig: `{"path": "/tmp/b.json"}`,
+		},
+		{
+			name:      "Override Changes the Type",
+			layer:     `{}`,
+			overrides: Overrides{"fileAssociationRepository.type": "MockFileAssociationRepository"},
+			wantType:  "MockFileAssociationRepository",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("HOME", dir)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+			t.Setenv("LFE_ALLOWED_ROOTS", dir)
+
+			writeConfig(t, filepath.Join(dir, "base.json"), string(base))
+			writeConfig(t, filepath.Join(dir, "config.d", "10-json.json"), `{"fileAssociationRepository": {"type": "JsonFileAssociationRepository", "config": {"path": "/tmp/a.json"}}}`)
+			writeConfig(t, filepath.Join(dir, "config.d", "20-layer.json"), tc.layer)
+
+			config, _, err := tc.overrides.Load(filepath.Join(dir, "base.json"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			repo := config.FileAssociationRepository
+			if repo.Type != tc.wantType {
+				t.Errorf("expected type %v, got %v", tc.wantType, repo.Type)
+			}
+			if string(repo.Config) != tc.wantConfig {
+				t.Errorf("expected config %q, got %q", tc.wantConfig, repo.Config)
+			}
+		})
+	}
+}
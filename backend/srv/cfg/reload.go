@@ -0,0 +1,91 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+)
+
+// restartOnly lists the settings that are only read when the server starts, by their json path
+var restartOnly = []string{
+	"port",
+	"hosts",
+	"disableTcp",
+	"unixSocket",
+	"ssl",
+	"timeouts",
+	"logging.format",
+	"logging.file",
+	"logging.maxSizeMb",
+	"logging.maxBackups",
+}
+
+// NeedsRestart reports whether a change returned by Changes only takes effect after a restart
+func NeedsRestart(change string) bool {
+	for _, path := range restartOnly {
+		if change == path || strings.HasPrefix(change, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes lists the json paths of the settings that differ between old and next, e.g. logging.level
+func Changes(old, next Config) []string {
+	if next.ShareLinks.generatedSecret && old.ShareLinks.generatedSecret {
+		// both secrets are random, ApplyLive keeps the old one
+		next.ShareLinks.Secret = old.ShareLinks.Secret
+	}
+
+	return changes(reflect.ValueOf(old), reflect.ValueOf(next), "")
+}
+
+func changes(old, next reflect.Value, prefix string) []string {
+	changed := []string{}
+
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		path := prefix + name
+
+		// repositories are swapped as a whole, so there is no point in listing their fields
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(RepositoryConfig{}) {
+			changed = append(changed, changes(old.Field(i), next.Field(i), path+".")...)
+			continue
+		}
+
+		if !reflect.DeepEqual(old.Field(i).Interface(), next.Field(i).Interface()) {
+			changed = append(changed, path)
+		}
+	}
+
+	return changed
+}
+
+// ApplyLive returns next with every setting that needs a restart taken from old, so a running server
+// can switch to it
+func ApplyLive(old, next Config) Config {
+	next.Port = old.Port
+	next.Hosts = old.Hosts
+	next.DisableTCP = old.DisableTCP
+	next.UnixSocket = old.UnixSocket
+	next.SSL = old.SSL
+	next.Timeouts = old.Timeouts
+	next.Logging.Format = old.Logging.Format
+	next.Logging.File = old.Logging.File
+	next.Logging.MaxSizeMB = old.Logging.MaxSizeMB
+	next.Logging.MaxBackups = old.Logging.MaxBackups
+
+	if next.ShareLinks.generatedSecret && old.ShareLinks.generatedSecret {
+		// a new random secret would invalidate every share link
+		next.ShareLinks.Secret = old.ShareLinks.Secret
+	}
+
+	return next
+}
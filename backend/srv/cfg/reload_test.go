@@ -0,0 +1,181 @@
+package cfg
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// Helper function to build a config that has every setting set
+func reloadConfig() Config {
+	return Config{
+		Port:                      3000,
+		Hosts:                     []string{"127.0.0.1"},
+		UnixSocket:                UnixSocket{Enabled: true, Path: "/run/user/1000/lfe.sock"},
+		Env:                       Development,
+		AppRepository:             RepositoryConfig{Type: "MockAppRepository"},
+		FileAssociationRepository: RepositoryConfig{Type: "JsonFileAssociationRepository", Config: json.RawMessage(`{"path":"/tmp/a.json"}`)},
+		ShareLinks:                ShareLinks{Secret: "secret", DefaultTTLSeconds: 60, MaxTTLSeconds: 120},
+		AllowedRoots:              []string{"/home/user"},
+		Timeouts:                  Timeouts{ShutdownSeconds: 30},
+		Logging:                   Logging{Level: "info", Format: "console"},
+	}
+}
+
+func TestChanges(t *testing.T) {
+	testCases := []struct {
+		name            string
+		modify          func(c *Config)
+		wantChanges     []string
+		wantNeedRestart []string
+	}{
+		{
+			name:        "Nothing changed",
+			modify:      func(c *Config) {},
+			wantChanges: []string{},
+		},
+		{
+			name:        "Live setting",
+			modify:      func(c *Config) { c.Logging.Level = "debug" },
+			wantChanges: []string{"logging.level"},
+		},
+		{
+			name:            "Restart only setting",
+			modify:          func(c *Config) { c.Port = 4000 },
+			wantChanges:     []string{"port"},
+			wantNeedRestart: []string{"port"},
+		},
+		{
+			name:            "Nested restart only setting",
+			modify:          func(c *Config) { c.UnixSocket.Path = "/tmp/lfe.sock" },
+			wantChanges:     []string{"unixSocket.path"},
+			wantNeedRestart: []string{"unixSocket.path"},
+		},
+		{
+			name: "Repository is reported as a whole",
+			modify: func(c *Config) {
+				c.FileAssociationRepository.Config = json.RawMessage(`{"path":"/tmp/b.json"}`)
+			},
+			wantChanges: []string{"fileAssociationRepository"},
+		},
+		{
+			name: "Live and restart only settings",
+			modify: func(c *Config) {
+				c.ReadOnly = true
+				c.AllowedRoots = []string{"/srv"}
+				c.Timeouts.ShutdownSeconds = 5
+				c.Logging.Format = "json"
+			},
+			wantChanges:     []string{"allowedRoots", "readOnly", "timeouts.shutdownSeconds", "logging.format"},
+			wantNeedRestart: []string{"timeouts.shutdownSeconds", "logging.format"},
+		},
+		{
+			name: "Generated secrets",
+			modify: func(c *Config) {
+				c.ShareLinks.Secret = "other"
+				c.ShareLinks.generatedSecret = true
+			},
+			wantChanges: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			old := reloadConfig()
+			old.ShareLinks.generatedSecret = true
+			next := reloadConfig()
+			next.ShareLinks.generatedSecret = true
+			tc.modify(&next)
+
+			changes := Changes(old, next)
+			if !slices.Equal(changes, tc.wantChanges) {
+				t.Errorf("Changes mismatch: got %v, want %v", changes, tc.wantChanges)
+			}
+
+			needRestart := []string{}
+			for _, change := range changes {
+				if NeedsRestart(change) {
+					needRestart = append(needRestart, change)
+				}
+			}
+			if !slices.Equal(needRestart, tc.wantNeedRestart) {
+				t.Errorf("Restart mismatch: got %v, want %v", needRestart, tc.wantNeedRestart)
+			}
+		})
+	}
+}
+
+func TestApplyLive(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(c *Config)
+		check  func(t *testing.T, applied Config)
+	}{
+		{
+			name:   "Live settings are applied",
+			modify: func(c *Config) { c.Logging.Level = "debug"; c.ReadOnly = true },
+			check: func(t *testing.T, applied Config) {
+				if applied.Logging.Level != "debug" || !applied.ReadOnly {
+					t.Errorf("Expected the live settings to be applied, but got level %v and read only %v", applied.Logging.Level, applied.ReadOnly)
+				}
+			},
+		},
+		{
+			name: "Restart only settings are kept",
+			modify: func(c *Config) {
+				c.Port = 4000
+				c.Hosts = []string{"0.0.0.0"}
+				c.DisableTCP = true
+				c.UnixSocket = UnixSocket{}
+				c.SSL = SSL{CertPath: "/cert.pem", KeyPath: "/key.pem"}
+				c.Timeouts = Timeouts{ShutdownSeconds: 1}
+				c.Logging = Logging{Level: "debug", Format: "json", File: "/var/log/lfe.log", MaxSizeMB: 1, MaxBackups: 1}
+			},
+			check: func(t *testing.T, applied Config) {
+				if applied.Logging.Level != "debug" {
+					t.Errorf("Expected the log level to be applied, but got %v", applied.Logging.Level)
+				}
+			},
+		},
+		{
+			name: "Generated secret is kept",
+			modify: func(c *Config) {
+				c.ShareLinks.Secret = "other"
+				c.ShareLinks.generatedSecret = true
+			},
+			check: func(t *testing.T, applied Config) {
+				if applied.ShareLinks.Secret != "secret" {
+					t.Errorf("Expected the old secret, but got %v", applied.ShareLinks.Secret)
+				}
+			},
+		},
+		{
+			name:   "Configured secret is applied",
+			modify: func(c *Config) { c.ShareLinks.Secret = "other" },
+			check: func(t *testing.T, applied Config) {
+				if applied.ShareLinks.Secret != "other" {
+					t.Errorf("Expected the new secret, but got %v", applied.ShareLinks.Secret)
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			old := reloadConfig()
+			old.ShareLinks.generatedSecret = true
+			next := reloadConfig()
+			tc.modify(&next)
+
+			applied := ApplyLive(old, next)
+			tc.check(t, applied)
+
+			// whatever is left to change must be live
+			for _, change := range Changes(old, applied) {
+				if NeedsRestart(change) {
+					t.Errorf("Expected %v to be kept until a restart", change)
+				}
+			}
+		})
+	}
+}
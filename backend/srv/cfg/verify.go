@@ -1,12 +1,37 @@
 package cfg
 
-import "fmt"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"golang-web-core/srv/auth"
+	"golang-web-core/util/logging"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
 
 func (c *Config) Verify() error {
-	if c.Port == 0 {
+	if c.Port == 0 && !c.DisableTCP {
 		return fmt.Errorf("port is required")
 	}
 
+	err := c.verifyHosts()
+	if err != nil {
+		return err
+	}
+
+	err = c.UnixSocket.verify()
+	if err != nil {
+		return err
+	}
+
+	if c.DisableTCP && !c.UnixSocket.Enabled {
+		return fmt.Errorf("tcp is disabled and the unix socket is not enabled, the server would not be reachable")
+	}
+
 	if c.Env != Development && c.Env != Production {
 		return fmt.Errorf("invalid environment: %v", c.Env)
 	}
@@ -23,5 +48,257 @@ func (c *Config) Verify() error {
 		}
 	}
 
+	err = c.ShareLinks.verify()
+	if err != nil {
+		return err
+	}
+
+	err = c.verifySandbox()
+	if err != nil {
+		return err
+	}
+
+	c.CORS.verify()
+
+	err = c.Logging.verify()
+	if err != nil {
+		return err
+	}
+
+	if c.AuthTokenPath == "" {
+		path, err := auth.DefaultTokenPath()
+		if err != nil {
+			return fmt.Errorf("no authTokenPath was configured and the config directory could not be determined: %v", err)
+		}
+		c.AuthTokenPath = path
+	} else if !filepath.IsAbs(c.AuthTokenPath) {
+		return fmt.Errorf("authTokenPath %v must be an absolute path", c.AuthTokenPath)
+	}
+
+	err = c.verifyAuthTokens()
+	if err != nil {
+		return err
+	}
+
+	err = c.Limits.verify()
+	if err != nil {
+		return err
+	}
+
+	return c.Timeouts.verify()
+}
+
+// DefaultHosts keep the api on the loopback interfaces unless hosts is configured
+var DefaultHosts = []string{"127.0.0.1", "::1"}
+
+func (c *Config) verifyHosts() error {
+	if len(c.Hosts) == 0 {
+		c.Hosts = slices.Clone(DefaultHosts)
+		return nil
+	}
+
+	for _, host := range c.Hosts {
+		if net.ParseIP(host) == nil {
+			return fmt.Errorf("hosts: %q is not an ip address", host)
+		}
+	}
+
+	return nil
+}
+
+func (t *Timeouts) verify() error {
+	if t.ReadHeaderSeconds < 0 || t.ReadSeconds < 0 || t.WriteSeconds < 0 || t.IdleSeconds < 0 || t.ShutdownSeconds < 0 {
+		return fmt.Errorf("timeouts must not be negative")
+	}
+
+	if t.ReadHeaderSeconds == 0 {
+		t.ReadHeaderSeconds = 10
+	}
+
+	if t.ShutdownSeconds == 0 {
+		t.ShutdownSeconds = 30
+	}
+
+	return nil
+}
+
+func (c *Config) verifyAuthTokens() error {
+	validScopes := []string{auth.ScopeRead, auth.ScopeWrite, auth.ScopeMetrics}
+	names := map[string]bool{}
+
+	for _, token := range c.AuthTokens {
+		if token.Name == "" {
+			return fmt.Errorf("every entry in authTokens needs a name")
+		}
+		if names[token.Name] {
+			return fmt.Errorf("the auth token %v is configured twice", token.Name)
+		}
+		names[token.Name] = true
+
+		if !filepath.IsAbs(token.Path) {
+			return fmt.Errorf("the path of auth token %v must be an absolute path", token.Name)
+		}
+		if filepath.Clean(token.Path) == filepath.Clean(c.AuthTokenPath) {
+			return fmt.Errorf("auth token %v uses the same path as the main token", token.Name)
+		}
+
+		if len(token.Scopes) == 0 {
+			return fmt.Errorf("auth token %v has no scopes", token.Name)
+		}
+		for _, scope := range token.Scopes {
+			if !slices.Contains(validScopes, scope) {
+				return fmt.Errorf("auth token %v has an invalid scope: %v, expected one of %v", token.Name, scope, strings.Join(validScopes, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *Limits) verify() error {
+	if l.MaxBodyMB == 0 {
+		l.MaxBodyMB = 10
+	}
+
+	if l.MaxBodyMB < 0 || l.RequestsPerSecond < 0 || l.Burst < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+
+	if l.RequestsPerSecond > 0 && l.Burst == 0 {
+		l.Burst = max(1, int(l.RequestsPerSecond))
+	}
+
+	return nil
+}
+
+func (c *Config) verifySandbox() error {
+	if len(c.AllowedRoots) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("no allowedRoots were configured and the home directory could not be determined: %v", err)
+		}
+		c.AllowedRoots = []string{home}
+	}
+
+	for i, root := range c.AllowedRoots {
+		if !filepath.IsAbs(root) {
+			return fmt.Errorf("allowed root %v must be an absolute path", root)
+		}
+
+		info, err := os.Stat(root)
+		if err != nil {
+			return fmt.Errorf("allowed root %v is not accessible: %v", root, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("allowed root %v is not a directory", root)
+		}
+
+		c.AllowedRoots[i] = filepath.Clean(root)
+	}
+
+	for i, path := range c.DeniedPaths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("denied path %v must be an absolute path", path)
+		}
+		c.DeniedPaths[i] = filepath.Clean(path)
+	}
+
+	return nil
+}
+
+func (l *Logging) verify() error {
+	_, err := logging.ParseLevel(l.Level)
+	if err != nil {
+		return err
+	}
+
+	if l.Format == "" {
+		l.Format = logging.FormatConsole
+	}
+	if l.Format != logging.FormatConsole && l.Format != logging.FormatJSON {
+		return fmt.Errorf("invalid log format: %v, expected %v or %v", l.Format, logging.FormatConsole, logging.FormatJSON)
+	}
+
+	if l.File != "" && !filepath.IsAbs(l.File) {
+		return fmt.Errorf("logging.file %v must be an absolute path", l.File)
+	}
+
+	if l.MaxSizeMB == 0 {
+		l.MaxSizeMB = 10
+	}
+	if l.MaxBackups == 0 {
+		l.MaxBackups = 5
+	}
+
+	if l.SlowRequestMs < 0 {
+		return fmt.Errorf("logging.slowRequestMs must not be negative")
+	}
+
+	return nil
+}
+
+func (c *CORS) verify() {
+	if len(c.AllowedMethods) == 0 {
+		c.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+
+	if len(c.AllowedHeaders) == 0 {
+		c.AllowedHeaders = []string{"Authorization", "Content-Type"}
+	}
+
+	if c.MaxAgeSeconds == 0 {
+		c.MaxAgeSeconds = 600
+	}
+}
+
+func (u *UnixSocket) verify() error {
+	if !u.Enabled {
+		return nil
+	}
+
+	if u.Path == "" {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			return fmt.Errorf("unixSocket.path was not set and XDG_RUNTIME_DIR is not available")
+		}
+		u.Path = filepath.Join(runtimeDir, "linux-file-explorer.sock")
+	}
+
+	if !filepath.IsAbs(u.Path) {
+		return fmt.Errorf("unixSocket.path %v must be an absolute path", u.Path)
+	}
+
+	return nil
+}
+
+func (s *ShareLinks) verify() error {
+	if s.Secret == "" {
+		secret := make([]byte, 32)
+		_, err := rand.Read(secret)
+		if err != nil {
+			return err
+		}
+		s.Secret = hex.EncodeToString(secret)
+		s.generatedSecret = true
+	} else if len(s.Secret) < 32 {
+		return fmt.Errorf("shareLinks.secret must be at least 32 characters long")
+	}
+
+	if s.DefaultTTLSeconds == 0 {
+		s.DefaultTTLSeconds = 24 * 60 * 60
+	}
+
+	if s.MaxTTLSeconds == 0 {
+		s.MaxTTLSeconds = 30 * 24 * 60 * 60
+	}
+
+	if s.DefaultTTLSeconds < 0 || s.MaxTTLSeconds < 0 {
+		return fmt.Errorf("shareLinks ttl values must be positive")
+	}
+
+	if s.DefaultTTLSeconds > s.MaxTTLSeconds {
+		return fmt.Errorf("shareLinks.defaultTtlSeconds (%v) is greater than shareLinks.maxTtlSeconds (%v)", s.DefaultTTLSeconds, s.MaxTTLSeconds)
+	}
+
 	return nil
 }
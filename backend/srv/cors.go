@@ -0,0 +1,74 @@
+package srv
+
+import (
+	"fmt"
+	"golang-web-core/srv/cfg"
+	"golang-web-core/srv/srverr"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type corsPolicy struct {
+	cfg.CORS
+}
+
+// allowsOrigin reports whether a browser page from origin may call the api. the opaque "null" origin of sandboxed
+// frames and local files is never allowed, not even by "*", since any page can produce it
+func (p corsPolicy) allowsOrigin(origin string) bool {
+	if origin == "null" {
+		return false
+	}
+	return slices.Contains(p.AllowedOrigins, "*") || slices.Contains(p.AllowedOrigins, origin)
+}
+
+func (p corsPolicy) setOriginHeaders(rw http.ResponseWriter, origin string) {
+	rw.Header().Set("Access-Control-Allow-Origin", origin)
+	rw.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Content-Disposition")
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+// withCORS applies the cors policy to every response and rejects state changing requests from origins
+// that aren't allowed, so a web page the user happens to have open can't drive the api
+func (s *Server) withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		policy := corsPolicy{s.Config.CORS}
+		origin := req.Header.Get("Origin")
+
+		rw.Header().Add("Vary", "Origin")
+
+		if origin != "" {
+			if policy.allowsOrigin(origin) {
+				policy.setOriginHeaders(rw, origin)
+			} else if isMutatingMethod(req.Method) {
+				srverr.Handle403(rw, fmt.Errorf("origin %v is not allowed", origin))
+				return
+			}
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+func (s *Server) HandleOptions(rw http.ResponseWriter, req *http.Request) {
+	policy := corsPolicy{s.Config.CORS}
+	origin := req.Header.Get("Origin")
+
+	if origin != "" && !policy.allowsOrigin(origin) {
+		srverr.Handle403(rw, fmt.Errorf("origin %v is not allowed", origin))
+		return
+	}
+
+	rw.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+	rw.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+	rw.Header().Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAgeSeconds))
+	rw.WriteHeader(http.StatusNoContent)
+}
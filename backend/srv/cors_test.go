@@ -0,0 +1,138 @@
+package srv
+
+import (
+	"golang-web-core/srv/cfg"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	cors := cfg.CORS{
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization"},
+		MaxAgeSeconds:  600,
+	}
+
+	testCases := []struct {
+		name           string
+		allowedOrigins []string
+		method         string
+		origin         string
+		wantStatus     int
+		wantAllowed    bool
+	}{
+		{
+			name:           "Preflight from an allowed origin",
+			allowedOrigins: []string{"https://files.example"},
+			method:         http.MethodOptions,
+			origin:         "https://files.example",
+			wantStatus:     http.StatusNoContent,
+			wantAllowed:    true,
+		},
+		{
+			name:           "Preflight from another origin",
+			allowedOrigins: []string{"https://files.example"},
+			method:         http.MethodOptions,
+			origin:         "https://evil.example",
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "Preflight from the null origin with a wildcard",
+			allowedOrigins: []string{"*"},
+			method:         http.MethodOptions,
+			origin:         "null",
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "Mutating request from an allowed origin",
+			allowedOrigins: []string{"https://files.example"},
+			method:         http.MethodPost,
+			origin:         "https://files.example",
+			wantStatus:     http.StatusOK,
+			wantAllowed:    true,
+		},
+		{
+			name:           "Mutating request from another origin",
+			allowedOrigins: []string{"https://files.example"},
+			method:         http.MethodDelete,
+			origin:         "https://evil.example",
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "Mutating request from any origin with a wildcard",
+			allowedOrigins: []string{"*"},
+			method:         http.MethodPost,
+			origin:         "https://evil.example",
+			wantStatus:     http.StatusOK,
+			wantAllowed:    true,
+		},
+		{
+			name:           "Mutating request from the null origin with a wildcard",
+			allowedOrigins: []string{"*"},
+			method:         http.MethodPost,
+			origin:         "null",
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "Mutating request from the null origin when it is listed",
+			allowedOrigins: []string{"null"},
+			method:         http.MethodPut,
+			origin:         "null",
+			wantStatus:     http.StatusForbidden,
+		},
+		{
+			name:           "Reading request from another origin",
+			allowedOrigins: []string{"https://files.example"},
+			method:         http.MethodGet,
+			origin:         "https://evil.example",
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:           "Mutating request without an origin",
+			allowedOrigins: []string{},
+			method:         http.MethodPost,
+			origin:         "",
+			wantStatus:     http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := cors
+			config.AllowedOrigins = tc.allowedOrigins
+			server := &Server{Config: cfg.Config{CORS: config}}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("OPTIONS /api/files", server.HandleOptions)
+			mux.HandleFunc("/api/files", func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tc.method, "/api/files", nil)
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			rw := httptest.NewRecorder()
+			server.withCORS(mux).ServeHTTP(rw, req)
+
+			if rw.Code != tc.wantStatus {
+				t.Errorf("Expected status %v, but got %v", tc.wantStatus, rw.Code)
+			}
+
+			allowed := rw.Header().Get("Access-Control-Allow-Origin") == tc.origin && tc.origin != ""
+			if allowed != tc.wantAllowed {
+				t.Errorf("Expected the origin to be allowed: %v, but got Access-Control-Allow-Origin %q", tc.wantAllowed, rw.Header().Get("Access-Control-Allow-Origin"))
+			}
+
+			if tc.method == http.MethodOptions && tc.wantStatus == http.StatusNoContent {
+				if got := rw.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+					t.Errorf("Expected Access-Control-Allow-Methods %q, but got %q", "GET, POST", got)
+				}
+				if got := rw.Header().Get("Access-Control-Max-Age"); got != "600" {
+					t.Errorf("Expected Access-Control-Max-Age 600, but got %q", got)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package srv
 
 import (
-	"golang-web-core/util"
+	"log/slog"
 	"net/http"
 )
 
@@ -11,6 +11,6 @@ type FileServer struct {
 }
 
 func (s FileServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	util.LogItalicColor("lightgray", "Serving %v%v to %v", s.Prefix, req.URL.Path, req.RemoteAddr)
+	slog.Info("serving public file", "path", s.Prefix+req.URL.Path, "remote_addr", req.RemoteAddr)
 	s.Handler.ServeHTTP(rw, req)
 }
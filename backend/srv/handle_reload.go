@@ -0,0 +1,93 @@
+package srv
+
+import (
+	"fmt"
+	"golang-web-core/srv/cfg"
+	"golang-web-core/util/logging"
+	"golang-web-core/util/systemd"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// RegisterHandleReload reloads the config on SIGHUP
+func (s *Server) RegisterHandleReload() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+
+	go func() {
+		for range c {
+			err := s.Reload()
+			if err != nil {
+				slog.Error("config reload failed, keeping the current config", "error", err)
+			}
+		}
+	}()
+}
+
+// Reload reads and verifies the config again and applies every change that does not need a restart.
+// running requests and jobs are not interrupted, new requests are served with the new config
+func (s *Server) Reload() error {
+	if s.LoadConfig == nil {
+		return fmt.Errorf("this server was not started from a config file")
+	}
+
+	err := systemd.Reloading()
+	if err != nil {
+		slog.Warn("could not notify systemd", "state", "RELOADING=1", "error", err)
+	}
+	defer notifySystemd("READY=1")
+
+	next, err := s.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	current := s.config()
+	previous := s.app()
+
+	changes := cfg.Changes(current, next)
+	if len(changes) == 0 {
+		slog.Info("config reloaded, nothing changed")
+		return nil
+	}
+
+	applied := []string{}
+	needRestart := []string{}
+	for _, change := range changes {
+		if cfg.NeedsRestart(change) {
+			needRestart = append(needRestart, change)
+		} else {
+			applied = append(applied, change)
+		}
+	}
+
+	next = cfg.ApplyLive(current, next)
+
+	if len(applied) > 0 {
+		built, err := buildServer(next, &previous, false)
+		if err != nil {
+			return err
+		}
+
+		logging.Level.Set(next.Logging.Options().Level)
+		s.use(built)
+		slog.Info("config reloaded", "applied", applied)
+
+		// requests that started before the reload may still be using the replaced repositories
+		time.AfterFunc(next.Timeouts.Shutdown(), func() {
+			err := previous.CloseReplaced(built.App)
+			if err != nil {
+				slog.Error("failed to close the repositories replaced by the config reload", "error", err)
+			}
+		})
+	}
+
+	if len(needRestart) > 0 {
+		slog.Warn("some config changes only take effect after a restart", "changes", needRestart)
+	}
+
+	return nil
+}
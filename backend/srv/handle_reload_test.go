@@ -0,0 +1,165 @@
+package srv
+
+import (
+	"bytes"
+	"errors"
+	"golang-web-core/domain"
+	"golang-web-core/repositories"
+	apprepo "golang-web-core/repositories/app"
+	fileassociationrepo "golang-web-core/repositories/file_association"
+	"golang-web-core/srv/cfg"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type closeRecorder struct {
+	closed chan struct{}
+}
+
+func (c *closeRecorder) Close() error {
+	close(c.closed)
+	return nil
+}
+
+type closingApps struct {
+	apprepo.MockAppRepository
+	*closeRecorder
+}
+
+type closingAssociations struct {
+	fileassociationrepo.MockFileAssociationRepository
+	*closeRecorder
+}
+
+func TestReloadClosesReplacedRepositories(t *testing.T) {
+	apps := closingApps{closeRecorder: &closeRecorder{closed: make(chan struct{})}}
+	associations := closingAssociations{closeRecorder: &closeRecorder{closed: make(chan struct{})}}
+
+	repositories.Apps.Register("ClosingAppRepository", repositories.NoConfig(func() (domain.AppRepository, error) {
+		return apps, nil
+	}))
+	repositories.FileAssociations.Register("ClosingFileAssociationRepository", repositories.NoConfig(func() (domain.FileAssociationRepository, error) {
+		return associations, nil
+	}))
+
+	config := testConfig(t)
+	config.Timeouts.ShutdownSeconds = 0
+	config.AppRepository = cfg.RepositoryConfig{Type: "ClosingAppRepository"}
+	config.FileAssociationRepository = cfg.RepositoryConfig{Type: "ClosingFileAssociationRepository"}
+
+	server, err := NewServer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.LoadConfig = func() (cfg.Config, error) {
+		next := config
+		next.FileAssociationRepository = cfg.RepositoryConfig{Type: "MockFileAssociationRepository"}
+		return next, nil
+	}
+
+	err = server.Reload()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	select {
+	case <-associations.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the replaced file association repository to be closed")
+	}
+
+	select {
+	case <-apps.closed:
+		t.Errorf("Expected the app repository that was taken over to stay open")
+	default:
+	}
+}
+
+func TestReload(t *testing.T) {
+	testCases := []struct {
+		name           string
+		modify         func(c *cfg.Config)
+		loadErr        error
+		expectErr      bool
+		wantReadOnly   bool
+		wantPort       int
+		wantRestartLog bool
+	}{
+		{
+			name:     "Nothing changed",
+			modify:   func(c *cfg.Config) {},
+			wantPort: 3000,
+		},
+		{
+			name:         "Live setting is applied",
+			modify:       func(c *cfg.Config) { c.ReadOnly = true },
+			wantReadOnly: true,
+			wantPort:     3000,
+		},
+		{
+			name:           "Restart only setting is reported but not applied",
+			modify:         func(c *cfg.Config) { c.Port = 4000 },
+			wantPort:       3000,
+			wantRestartLog: true,
+		},
+		{
+			name:           "Live and restart only settings",
+			modify:         func(c *cfg.Config) { c.ReadOnly = true; c.Port = 4000 },
+			wantReadOnly:   true,
+			wantPort:       3000,
+			wantRestartLog: true,
+		},
+		{
+			name:      "Invalid config is not applied",
+			modify:    func(c *cfg.Config) { c.ReadOnly = true },
+			loadErr:   errors.New("invalid config"),
+			expectErr: true,
+			wantPort:  3000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(logs, nil)))
+			t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+			config := testConfig(t)
+			config.Port = 3000
+
+			server, err := NewServer(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			server.LoadConfig = func() (cfg.Config, error) {
+				next := config
+				tc.modify(&next)
+				return next, tc.loadErr
+			}
+
+			err = server.Reload()
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("Expected error %v, but got: %v", tc.expectErr, err)
+			}
+
+			applied := server.config()
+			if applied.ReadOnly != tc.wantReadOnly {
+				t.Errorf("Expected read only %v, but got %v", tc.wantReadOnly, applied.ReadOnly)
+			}
+			if applied.Port != tc.wantPort {
+				t.Errorf("Expected port %v, but got %v", tc.wantPort, applied.Port)
+			}
+			if server.app().Config.ReadOnly != tc.wantReadOnly {
+				t.Errorf("Expected the controllers to use read only %v", tc.wantReadOnly)
+			}
+
+			restartLog := strings.Contains(logs.String(), "only take effect after a restart") && strings.Contains(logs.String(), "changes=[port]")
+			if restartLog != tc.wantRestartLog {
+				t.Errorf("Expected the port to be reported as needing a restart: %v, but got logs %v", tc.wantRestartLog, logs.String())
+			}
+		})
+	}
+}
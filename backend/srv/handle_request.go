@@ -6,9 +6,12 @@ import (
 	"golang-web-core/srv/cfg"
 	"golang-web-core/srv/route"
 	"golang-web-core/util"
-	"log"
+	"golang-web-core/util/logging"
+	"golang-web-core/util/metrics"
+	"log/slog"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -22,77 +25,85 @@ func generateRequestID(length int) string {
 	return string(result)
 }
 
-func SetRequestID(req *http.Request) {
+func SetRequestID(rw http.ResponseWriter, req *http.Request) {
 	requestId := generateRequestID(16)
 
 	req.Header.Set("X-Request-ID", requestId)
+	rw.Header().Set("X-Request-ID", requestId)
 }
 
 func HandleRequest(appController controllers.ApplicationController, route route.Route) http.HandlerFunc {
-	return func(rw http.ResponseWriter, req *http.Request) {
-		SetRequestID(req)
+	return func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rw := newResponseRecorder(w)
 
-		logRequest(req)
+		SetRequestID(rw, req)
+		req = req.WithContext(logging.WithRequestID(req.Context(), req.Header.Get("X-Request-ID")))
 
-		params, err := util.GetParams(req)
-		if err == nil {
-			if appController.Config.Env == cfg.Development {
-				log.Printf("%v Params: %v\n", req.Header.Get("X-Request-ID"), params)
+		logRequest(req.Context(), req)
+
+		controller := appController.Controllers[route.ControllerName]
+
+		// the params are parsed inside of the route middlewares so body size limits apply to them
+		handler := func(rw http.ResponseWriter, req *http.Request) {
+			if route.ContextParams {
+				params, err := util.GetParams(req)
+				if err == nil {
+					if appController.Config.Env == cfg.Development {
+						slog.DebugContext(req.Context(), "params", "params", params)
+					}
+				}
+				if params == nil {
+					params = map[string]any{}
+				}
+
+				req = req.WithContext(context.WithValue(req.Context(), util.ParamsKey, params))
 			}
-		}
-		if params == nil {
-			params = map[string]any{}
+
+			controller.BeforeAction(route.Handler)(rw, req)
 		}
 
-		reqWithParams := req.WithContext(context.WithValue(req.Context(), util.ParamsKey, params))
+		aborted := serveRecovered(rw, req, route.Pattern, appController.BeforeAction(route.WithMiddlewares(handler)))
 
-		controller := appController.Controllers[route.ControllerName]
+		duration := time.Since(start)
+		logFinished(rw, req, duration, appController.Config.Logging.SlowRequestThreshold())
 
-		appController.BeforeAction(controller.BeforeAction(route.Handler))(rw, reqWithParams)
+		metrics.HTTPRequests.With(route.Method, route.Pattern, strconv.Itoa(rw.Status())).Inc()
+		metrics.HTTPRequestDuration.With(route.Method, route.Pattern).Observe(duration.Seconds())
 
-		logFinished(rw, reqWithParams)
+		if aborted {
+			panic(http.ErrAbortHandler)
+		}
 	}
 }
 
-func HandleOptions(rw http.ResponseWriter, req *http.Request) {
-	rw.Header().Set("Access-Control-Allow-Origin", "*")
-	rw.Header().Set("Access-Control-Allow-Methods", "*")
-	rw.Header().Set("Access-Control-Allow-Headers", "*")
-	rw.WriteHeader(http.StatusOK)
+func logRequest(ctx context.Context, req *http.Request) {
+	slog.InfoContext(ctx, "request started", "method", req.Method, "path", req.URL.Path, "remote_addr", req.RemoteAddr)
 }
 
-func logRequest(req *http.Request) {
-	color := "255;255;255"
-
-	switch req.Method {
-	case http.MethodGet:
-		color = "0;0;255"
-	case http.MethodConnect:
-		color = "0;0;255"
-	case http.MethodOptions:
-		color = "0;0;255"
-	case http.MethodTrace:
-		color = "0;0;255"
-	case http.MethodPost:
-		color = "100;255;100"
-	case http.MethodPatch:
-		color = "255;255;0"
-	case http.MethodPut:
-		color = "255;255;0"
-	case http.MethodDelete:
-		color = "255;0;0"
+func logFinished(rw *responseRecorder, req *http.Request, duration time.Duration, slowThreshold time.Duration) {
+	attrs := []any{
+		"method", req.Method,
+		"path", req.URL.Path,
+		"status", rw.Status(),
+		"bytes", rw.Bytes(),
+		"duration_ms", float64(duration.Microseconds()) / 1000,
+		"remote_addr", req.RemoteAddr,
 	}
 
-	requestID := req.Header.Get("X-Request-ID")
-	log.Printf("%v Started \033[38;2;%vm%v\033[0m %v for %v\n", requestID, color, req.Method, req.URL.Path, req.RemoteAddr)
-}
-
-func logFinished(rw http.ResponseWriter, req *http.Request) {
-	requestID := req.Header.Get("X-Request-ID")
+	level := slog.LevelInfo
+	msg := "request finished"
+	switch {
+	case rw.Status() >= http.StatusInternalServerError:
+		level = slog.LevelError
+		msg = "request failed"
+	case rw.Status() >= http.StatusBadRequest:
+		level = slog.LevelWarn
+		msg = "request finished with error"
+	}
+	slog.Log(req.Context(), level, msg, attrs...)
 
-	if rw.Header().Get("Content-Type") == "text/plain; charset=utf-8" {
-		log.Printf("%v %v %v finished with error, remote address: %v\n", requestID, req.Method, req.URL.Path, req.RemoteAddr)
-	} else {
-		log.Printf("%v Finished %v %v for %v\n", requestID, req.Method, req.URL.Path, req.RemoteAddr)
+	if slowThreshold > 0 && duration >= slowThreshold {
+		slog.WarnContext(req.Context(), "slow request", "method", req.Method, "path", req.URL.Path, "duration_ms", duration.Milliseconds(), "threshold_ms", slowThreshold.Milliseconds())
 	}
 }
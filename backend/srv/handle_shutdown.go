@@ -2,29 +2,77 @@ package srv
 
 import (
 	"context"
-	"golang-web-core/util"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"time"
+	"sync"
+	"syscall"
 )
 
+// RegisterHandleShutdown shuts the server down gracefully on SIGINT or SIGTERM. a second signal exits immediately
 func (s *Server) RegisterHandleShutdown(httpServer *http.Server) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
 	go func() {
-		for sig := range c {
-			if sig == os.Interrupt {
-				log.Println("Gracefully shutting down...")
-				err := httpServer.Shutdown(ctx)
-				if err != nil {
-					util.LogFatal(err)
-				}
+		sig := <-c
+		slog.Info("gracefully shutting down", "signal", sig.String(), "timeout", s.config().Timeouts.Shutdown().String())
+
+		done := make(chan struct{})
+		go func() {
+			select {
+			case sig := <-c:
+				slog.Error("received a second signal, exiting without waiting for requests and jobs", "signal", sig.String())
+				os.Exit(1)
+			case <-done:
 			}
+		}()
+
+		err := s.Shutdown(httpServer)
+		// once the server is shut down a signal no longer concerns it
+		signal.Stop(c)
+		close(done)
+		s.shutdownDone <- err
+	}()
+}
+
+// Shutdown stops accepting connections and jobs, waits up to the configured shutdown timeout for in-flight
+// requests and jobs, cancels whatever is left and then closes the repositories
+func (s *Server) Shutdown(httpServer *http.Server) error {
+	notifySystemd("STOPPING=1")
+
+	// the deadline starts now rather than when the server started
+	ctx, cancel := context.WithTimeout(context.Background(), s.config().Timeouts.Shutdown())
+	defer cancel()
+
+	app := s.app()
+
+	var wg sync.WaitGroup
+	var httpErr, jobsErr error
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		httpErr = httpServer.Shutdown(ctx)
+		if httpErr != nil {
+			slog.Warn("requests did not finish before the shutdown deadline, closing their connections", "error", httpErr)
+			httpServer.Close()
 		}
 	}()
+	go func() {
+		defer wg.Done()
+		jobsErr = app.Jobs.Drain(ctx)
+	}()
+	wg.Wait()
+
+	err := app.Close()
+	if err != nil {
+		slog.Error("failed to close repositories", "error", err)
+		return err
+	}
+
+	// running out of time is expected with long jobs, so it is logged but not treated as a failure
+	slog.Info("shutdown complete", "timed_out", httpErr != nil || jobsErr != nil)
+	return nil
 }
@@ -0,0 +1,159 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	lockName      = "server.lock"
+	discoveryName = "server.json"
+)
+
+// Discovery is written next to the lock once the server is listening, so clients like the frontend can find it
+// instead of assuming a port. it is removed on shutdown, after a crash the pid in it no longer exists
+type Discovery struct {
+	PID int `json:"pid"`
+	// URL is the address of the tcp listener, empty when tcp is disabled
+	URL        string    `json:"url,omitempty"`
+	Port       int       `json:"port,omitempty"`
+	UnixSocket string    `json:"unixSocket,omitempty"`
+	TokenPath  string    `json:"authTokenPath"`
+	Version    string    `json:"version"`
+	StartedAt  time.Time `json:"startedAt"`
+}
+
+// RunningError is returned by Acquire when another server already holds the lock
+type RunningError struct {
+	// Discovery is empty when the other server has not published it yet
+	Discovery Discovery
+}
+
+func (e *RunningError) Error() string {
+	d := e.Discovery
+	if d.PID == 0 {
+		return "another server is already starting"
+	}
+
+	msg := fmt.Sprintf("another server is already running (pid %v", d.PID)
+	if d.URL != "" {
+		msg += ", " + d.URL
+	}
+	if d.UnixSocket != "" {
+		msg += ", unix socket " + d.UnixSocket
+	}
+	return msg + ")"
+}
+
+// Dir is where the lock and discovery file live, $XDG_RUNTIME_DIR/linux-file-explorer. without XDG_RUNTIME_DIR
+// a directory in the temp dir that only the current user can access is used
+func Dir() string {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(runtimeDir) {
+		return filepath.Join(runtimeDir, "linux-file-explorer")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("linux-file-explorer-%v", os.Getuid()))
+}
+
+// prepareDir creates dir and makes sure nobody else could have planted files in it
+func prepareDir(dir string) error {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	var stat unix.Stat_t
+	err = unix.Lstat(dir, &stat)
+	if err != nil {
+		return &os.PathError{Op: "lstat", Path: dir, Err: err}
+	}
+	if stat.Mode&unix.S_IFMT != unix.S_IFDIR || int(stat.Uid) != os.Getuid() || stat.Mode&0o077 != 0 {
+		return fmt.Errorf("%v must be a directory owned by the current user that nobody else can access", dir)
+	}
+	return nil
+}
+
+// Lock is held by the running server for as long as it runs. the kernel releases it when the process exits, so
+// a crash never leaves a stale lock behind
+type Lock struct {
+	dir  string
+	file *os.File
+}
+
+// Acquire takes the lock in dir, or returns a *RunningError when another server holds it
+func Acquire(dir string) (*Lock, error) {
+	err := prepareDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, lockName), os.O_RDWR|os.O_CREATE|unix.O_NOFOLLOW, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = unix.Flock(int(file.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+	if errors.Is(err, unix.EWOULDBLOCK) {
+		file.Close()
+		discovery, _ := read(dir)
+		return nil, &RunningError{Discovery: discovery}
+	}
+	if err != nil {
+		file.Close()
+		return nil, &os.PathError{Op: "flock", Path: file.Name(), Err: err}
+	}
+
+	// a discovery file left by a server that crashed is out of date
+	os.Remove(filepath.Join(dir, discoveryName))
+
+	return &Lock{dir: dir, file: file}, nil
+}
+
+// Publish writes the discovery file. it is replaced atomically so readers never see half of it
+func (l *Lock) Publish(discovery Discovery) error {
+	bytes, err := json.MarshalIndent(discovery, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(l.dir, discoveryName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(bytes)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(l.dir, discoveryName))
+}
+
+// Release removes the discovery file and releases the lock
+func (l *Lock) Release() error {
+	err := os.Remove(filepath.Join(l.dir, discoveryName))
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	return errors.Join(err, l.file.Close())
+}
+
+func read(dir string) (Discovery, error) {
+	bytes, err := os.ReadFile(filepath.Join(dir, discoveryName))
+	if err != nil {
+		return Discovery{}, err
+	}
+
+	discovery := Discovery{}
+	err = json.Unmarshal(bytes, &discovery)
+	return discovery, err
+}
@@ -0,0 +1,199 @@
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	published := Discovery{PID: 4242, URL: "http://127.0.0.1:3000", Port: 3000, TokenPath: "/home/user/.config/token", Version: "1.0.0", StartedAt: time.Unix(1700000000, 0).UTC()}
+
+	testCases := []struct {
+		name          string
+		mode          os.FileMode
+		stale         bool
+		running       bool
+		publish       bool
+		expectErr     bool
+		wantRunning   string
+		wantDiscovery bool
+	}{
+		{
+			name: "First instance",
+			mode: 0o700,
+		},
+		{
+			name:  "Discovery file left by a crashed server is removed",
+			mode:  0o700,
+			stale: true,
+		},
+		{
+			name:        "Second instance while the first is starting",
+			mode:        0o700,
+			running:     true,
+			wantRunning: "another server is already starting",
+		},
+		{
+			name:          "Second instance after the first published its discovery file",
+			mode:          0o700,
+			running:       true,
+			publish:       true,
+			wantRunning:   "another server is already running (pid 4242, http://127.0.0.1:3000)",
+			wantDiscovery: true,
+		},
+		{
+			name:      "Directory other users can access",
+			mode:      0o755,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "linux-file-explorer")
+			err := os.Mkdir(dir, tc.mode)
+			if err == nil {
+				err = os.Chmod(dir, tc.mode)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.stale {
+				err := os.WriteFile(filepath.Join(dir, discoveryName), []byte(`{"pid":1}`), 0o600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tc.running {
+				first, err := Acquire(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer first.Release()
+
+				if tc.publish {
+					err := first.Publish(published)
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			lock, err := Acquire(dir)
+			if tc.expectErr {
+				if err == nil {
+					lock.Release()
+					t.Fatalf("Expected an error, but got nil")
+				}
+				return
+			}
+
+			if tc.wantRunning != "" {
+				var running *RunningError
+				if !errors.As(err, &running) {
+					t.Fatalf("Expected a RunningError, but got: %v", err)
+				}
+				if running.Error() != tc.wantRunning {
+					t.Errorf("Expected %q, but got %q", tc.wantRunning, running.Error())
+				}
+				if tc.wantDiscovery && running.Discovery != published {
+					t.Errorf("Expected the published discovery %+v, but got %+v", published, running.Discovery)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			defer lock.Release()
+
+			_, err = os.Stat(filepath.Join(dir, discoveryName))
+			if !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Expected no discovery file before publishing, but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestPublishAndRelease(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "linux-file-explorer")
+
+	lock, err := Acquire(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	discovery := Discovery{PID: os.Getpid(), UnixSocket: "/run/user/1000/lfe.sock", Version: "1.0.0", StartedAt: time.Unix(1700000000, 0).UTC()}
+	err = lock.Publish(discovery)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	read, err := read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != discovery {
+		t.Errorf("Expected %+v, but got %+v", discovery, read)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, discoveryName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the discovery file to be private, but got %v", info.Mode().Perm())
+	}
+
+	err = lock.Release()
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != lockName {
+			t.Errorf("Expected only the lock file to be left, but found %v", entry.Name())
+		}
+	}
+
+	// the lock can be taken again once it was released
+	lock, err = Acquire(dir)
+	if err != nil {
+		t.Fatalf("Expected the lock to be free, but got: %v", err)
+	}
+	lock.Release()
+}
+
+func TestDir(t *testing.T) {
+	private := filepath.Join(os.TempDir(), "linux-file-explorer-"+strconv.Itoa(os.Getuid()))
+
+	testCases := []struct {
+		name       string
+		runtimeDir string
+		want       string
+	}{
+		{name: "Runtime dir", runtimeDir: "/run/user/1000", want: "/run/user/1000/linux-file-explorer"},
+		{name: "Relative runtime dir is ignored", runtimeDir: "run", want: private},
+		{name: "No runtime dir", want: private},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("XDG_RUNTIME_DIR", tc.runtimeDir)
+
+			dir := Dir()
+			if dir != tc.want {
+				t.Errorf("Expected %v, but got %v", tc.want, dir)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"golang-web-core/srv/auth"
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"net/http"
+)
+
+// Authenticate rejects requests without a valid bearer token with 401 and adds the principal of the token to the
+// request context. paths for which isPublic reports true are let through without a token
+func Authenticate(tokens []auth.Token, isPublic func(path string) bool) route.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			if isPublic(req.URL.Path) {
+				next(rw, req)
+				return
+			}
+
+			principal, err := auth.Authenticate(req, tokens)
+			if err != nil {
+				rw.Header().Set("WWW-Authenticate", `Bearer realm="linux-file-explorer"`)
+				srverr.Handle401(rw, err)
+				return
+			}
+
+			next(rw, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+		}
+	}
+}
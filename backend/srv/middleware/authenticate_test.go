@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"golang-web-core/srv/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthenticateAndRequireScope(t *testing.T) {
+	tokens := []auth.Token{
+		{Name: "main", Value: "main-token", Scopes: []string{auth.ScopeAll}},
+		{Name: "reader", Value: "reader-token", Scopes: []string{auth.ScopeRead}},
+	}
+	isPublic := func(path string) bool {
+		return path == "/healthz"
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		header        string
+		scope         string
+		wantStatus    int
+		wantChallenge bool
+	}{
+		{
+			name:       "Public path without a token",
+			path:       "/healthz",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "Missing token",
+			path:          "/api/files",
+			scope:         auth.ScopeRead,
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: true,
+		},
+		{
+			name:          "Invalid token",
+			path:          "/api/files",
+			header:        "Bearer wrong-token",
+			scope:         auth.ScopeRead,
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: true,
+		},
+		{
+			name:       "Token with the scope",
+			path:       "/api/files",
+			header:     "Bearer reader-token",
+			scope:      auth.ScopeRead,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Token without the scope",
+			path:       "/api/files/copy",
+			header:     "Bearer reader-token",
+			scope:      auth.ScopeWrite,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Main token grants every scope",
+			path:       "/metrics",
+			header:     "Bearer main-token",
+			scope:      auth.ScopeMetrics,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			}
+			if tc.scope != "" {
+				handler = RequireScope(tc.scope)(handler)
+			}
+			handler = Authenticate(tokens, isPublic)(handler)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rw := httptest.NewRecorder()
+			handler(rw, req)
+
+			if rw.Code != tc.wantStatus {
+				t.Errorf("Expected status %v, but got %v: %v", tc.wantStatus, rw.Code, rw.Body.String())
+			}
+			if challenge := rw.Header().Get("WWW-Authenticate") != ""; challenge != tc.wantChallenge {
+				t.Errorf("Expected a WWW-Authenticate header: %v, but got %q", tc.wantChallenge, rw.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
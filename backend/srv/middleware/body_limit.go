@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"fmt"
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"net/http"
+)
+
+// MaxBodySize rejects request bodies larger than maxBytes with a 413
+func MaxBodySize(maxBytes int64) route.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			if req.ContentLength > maxBytes {
+				srverr.HandleError(http.StatusRequestEntityTooLarge, rw, fmt.Errorf("the request body may not be larger than %v bytes", maxBytes))
+				return
+			}
+
+			if req.Body != nil {
+				req.Body = http.MaxBytesReader(rw, req.Body, maxBytes)
+			}
+
+			next(rw, req)
+		}
+	}
+}
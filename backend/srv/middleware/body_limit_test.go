@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		contentLength int64
+		wantStatus    int
+		wantReadError bool
+	}{
+		{
+			name:          "Body within the limit",
+			body:          "0123456789",
+			contentLength: 10,
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:          "Declared length over the limit",
+			body:          "0123456789ab",
+			contentLength: 12,
+			wantStatus:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:          "Chunked body over the limit",
+			body:          "0123456789ab",
+			contentLength: -1,
+			wantStatus:    http.StatusOK,
+			wantReadError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var readErr error
+			handler := MaxBodySize(10)(func(rw http.ResponseWriter, req *http.Request) {
+				_, readErr = io.ReadAll(req.Body)
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/files/copy", strings.NewReader(tc.body))
+			req.ContentLength = tc.contentLength
+			rw := httptest.NewRecorder()
+			handler(rw, req)
+
+			if rw.Code != tc.wantStatus {
+				t.Errorf("Expected status %v, but got %v", tc.wantStatus, rw.Code)
+			}
+
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(readErr, &maxBytesErr) != tc.wantReadError {
+				t.Errorf("Expected a MaxBytesError: %v, but got: %v", tc.wantReadError, readErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"fmt"
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter is a token bucket per client that refills at perSecond up to burst. it outlives config reloads, so
+// the buckets are kept when the rate changes
+type RateLimiter struct {
+	mu        sync.Mutex
+	perSecond float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter allows each client perSecond requests on average with bursts of up to burst requests. a rate
+// of 0 disables the limit
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	l := &RateLimiter{buckets: map[string]*bucket{}}
+	l.SetRate(perSecond, burst)
+	return l
+}
+
+// SetRate changes the limit without forgetting how many requests each client has made
+func (l *RateLimiter) SetRate(perSecond float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.perSecond = perSecond
+	l.burst = float64(max(burst, 1))
+}
+
+// allow takes a token from the client's bucket. when it is empty it returns how long until the next token
+func (l *RateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.perSecond <= 0 {
+		return true, 0
+	}
+
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, lastSeen: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.lastSeen).Seconds()*l.perSecond)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+	}
+	b.tokens--
+
+	return true, 0
+}
+
+// sweep forgets clients whose bucket has been full for a while
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(l.burst / l.perSecond * float64(time.Second))
+	for client, b := range l.buckets {
+		if now.Sub(b.lastSeen) > refill {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// RateLimit rejects requests with a 429 while the client is over the limit of limiter. the limit is shared by
+// every route the returned middleware is applied to
+func RateLimit(limiter *RateLimiter) route.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			ok, retryAfter := limiter.allow(clientKey(req), time.Now())
+			if !ok {
+				rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				srverr.HandleError(http.StatusTooManyRequests, rw, fmt.Errorf("too many requests, try again in %v", retryAfter.Round(time.Millisecond)))
+				return
+			}
+
+			next(rw, req)
+		}
+	}
+}
+
+func clientKey(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		// unix socket connections all belong to the same user
+		return req.RemoteAddr
+	}
+	return host
+}
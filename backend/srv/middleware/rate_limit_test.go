@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type request struct {
+		client string
+		after  time.Duration
+		want   bool
+	}
+
+	testCases := []struct {
+		name      string
+		perSecond float64
+		burst     int
+		requests  []request
+	}{
+		{
+			name:      "Burst then rejected",
+			perSecond: 1,
+			burst:     2,
+			requests: []request{
+				{client: "a", after: 0, want: true},
+				{client: "a", after: 0, want: true},
+				{client: "a", after: 0, want: false},
+			},
+		},
+		{
+			name:      "Refills over time",
+			perSecond: 2,
+			burst:     1,
+			requests: []request{
+				{client: "a", after: 0, want: true},
+				{client: "a", after: 100 * time.Millisecond, want: false},
+				{client: "a", after: 500 * time.Millisecond, want: true},
+			},
+		},
+		{
+			name:      "Clients have their own buckets",
+			perSecond: 1,
+			burst:     1,
+			requests: []request{
+				{client: "a", after: 0, want: true},
+				{client: "a", after: 0, want: false},
+				{client: "b", after: 0, want: true},
+			},
+		},
+		{
+			name:      "Disabled",
+			perSecond: 0,
+			burst:     0,
+			requests: []request{
+				{client: "a", after: 0, want: true},
+				{client: "a", after: 0, want: true},
+				{client: "a", after: 0, want: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tc.perSecond, tc.burst)
+			now := start
+
+			for i, r := range tc.requests {
+				now = now.Add(r.after)
+				ok, retryAfter := limiter.allow(r.client, now)
+				if ok != r.want {
+					t.Errorf("Request %v: expected allowed %v, but got %v", i, r.want, ok)
+				}
+				if !ok && retryAfter <= 0 {
+					t.Errorf("Request %v: expected a positive retry after, but got %v", i, retryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterSetRateKeepsBuckets(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(1, 1)
+
+	ok, _ := limiter.allow("a", now)
+	if !ok {
+		t.Fatal("Expected the first request to be allowed")
+	}
+
+	// a reload that only changes the rate must not hand out a fresh burst
+	limiter.SetRate(1, 5)
+	ok, _ = limiter.allow("a", now)
+	if ok {
+		t.Errorf("Expected the client to still be limited after the rate changed")
+	}
+
+	limiter.SetRate(0, 0)
+	ok, _ = limiter.allow("a", now)
+	if !ok {
+		t.Errorf("Expected requests to be allowed once the limit is disabled")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := RateLimit(NewRateLimiter(1, 1))(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	wantStatus := []int{http.StatusOK, http.StatusTooManyRequests}
+	for i, want := range wantStatus {
+		req := httptest.NewRequest(http.MethodPost, "/api/files/copy", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rw := httptest.NewRecorder()
+		handler(rw, req)
+
+		if rw.Code != want {
+			t.Errorf("Request %v: expected status %v, but got %v", i, want, rw.Code)
+		}
+		if want == http.StatusTooManyRequests && rw.Header().Get("Retry-After") != "1" {
+			t.Errorf("Expected Retry-After 1, but got %q", rw.Header().Get("Retry-After"))
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"fmt"
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"net/http"
+)
+
+// ReadOnly rejects the request while isReadOnly reports true
+func ReadOnly(isReadOnly func() bool) route.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			if isReadOnly() {
+				srverr.Handle403(rw, fmt.Errorf("the server is in read-only mode"))
+				return
+			}
+
+			next(rw, req)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"fmt"
+	"golang-web-core/srv/auth"
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"net/http"
+)
+
+// RequireScope only lets requests through if the token they were authenticated with grants scope
+func RequireScope(scope string) route.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			principal, ok := auth.PrincipalFromContext(req.Context())
+			if !ok || !principal.HasScope(scope) {
+				srverr.Handle403(rw, fmt.Errorf("this token is missing the %v scope", scope))
+				return
+			}
+
+			next(rw, req)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package openapi
+
+import (
+	"golang-web-core/srv/route"
+	"golang-web-core/srv/srverr"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+// Generate builds an openapi 3 document from the registered routes. isPublic reports which
+// paths can be called without the bearer token
+func Generate(title, version string, routes []route.Route, isPublic func(path string) bool) Document {
+	g := generator{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+
+	doc := Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]map[string]Operation{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+			},
+		},
+		Security: []SecurityRequirement{{"bearerAuth": {}}},
+	}
+
+	sorted := append([]route.Route{}, routes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Pattern != sorted[j].Pattern {
+			return sorted[i].Pattern < sorted[j].Pattern
+		}
+		return sorted[i].Method < sorted[j].Method
+	})
+
+	errorSchema := g.schema(reflect.TypeOf(srverr.ErrorBody{}))
+
+	for _, r := range sorted {
+		path := pathParamPattern.ReplaceAllString(r.Pattern, "{$1}")
+
+		op := Operation{
+			OperationID: operationID(r),
+			Summary:     r.Summary,
+			Tags:        []string{strings.TrimSuffix(r.ControllerName, "Controller")},
+			Responses:   map[string]Response{},
+		}
+
+		var requestType reflect.Type
+		bound := []Parameter{}
+		if r.Request != nil {
+			requestType = reflect.TypeOf(r.Request)
+			bound = g.parameters(requestType)
+		}
+
+		for _, match := range pathParamPattern.FindAllStringSubmatch(r.Pattern, -1) {
+			param := Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}}
+			if i := slices.IndexFunc(bound, func(p Parameter) bool { return p.In == "path" && p.Name == param.Name }); i >= 0 {
+				param.Schema = bound[i].Schema
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+
+		for _, param := range bound {
+			if param.In == "query" {
+				op.Parameters = append(op.Parameters, param)
+			}
+		}
+
+		if requestType != nil && r.Method != http.MethodGet && r.Method != http.MethodDelete && hasBody(requestType) {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: g.schema(requestType)}},
+			}
+		}
+
+		status := r.Status
+		if status == 0 {
+			status = http.StatusOK
+			if r.Response == nil {
+				status = http.StatusNoContent
+			}
+		}
+		response := Response{Description: http.StatusText(status)}
+		if r.Response != nil {
+			response.Content = map[string]MediaType{"application/json": {Schema: g.schema(reflect.TypeOf(r.Response))}}
+		}
+		op.Responses[strconv.Itoa(status)] = response
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+		}
+
+		if isPublic != nil && isPublic(r.Pattern) {
+			op.Security = []SecurityRequirement{{}}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]Operation{}
+		}
+		doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	return doc
+}
+
+// operationID uses the name of the route or of the handler method, e.g. GetAllApps
+func operationID(r route.Route) string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	name := runtime.FuncForPC(reflect.ValueOf(r.Handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	if name == "" || strings.HasPrefix(name, "func") {
+		name = strings.ToLower(r.Method)
+		for _, segment := range strings.Split(pathParamPattern.ReplaceAllString(r.Pattern, "$1"), "/") {
+			name += exportedName(segment)
+		}
+	}
+	return name
+}
+
+// hasBody reports whether t has any fields that are decoded from the json body
+func hasBody(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() != reflect.Struct || len(jsonFields(t)) > 0
+}
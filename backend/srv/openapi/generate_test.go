@@ -0,0 +1,174 @@
+package openapi
+
+import (
+	"golang-web-core/srv/route"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+type itemParams struct {
+	ID      int    `path:"id" json:"-"`
+	Verbose bool   `query:"verbose" json:"-"`
+	Fields  string `query:"fields" json:"-" validate:"required"`
+}
+
+type pageParams struct {
+	Limit int `query:"limit" json:"-"`
+}
+
+type listParams struct {
+	pageParams
+	Path string `path:"path" json:"-"`
+}
+
+type itemBody struct {
+	ID   int    `path:"id" json:"-"`
+	Name string `json:"name" validate:"required"`
+}
+
+type item struct {
+	Name string `json:"name"`
+}
+
+type testController struct{}
+
+func (c testController) GetItem(rw http.ResponseWriter, req *http.Request) {}
+
+func TestGenerate(t *testing.T) {
+	routes := []route.Route{
+		{Pattern: "/api/items/{id}", Method: http.MethodGet, Handler: testController{}.GetItem, ControllerName: "ItemsController", Request: itemParams{}, Response: item{}},
+		{Pattern: "/api/items/{id}", Method: http.MethodPut, Name: "UpdateItem", ControllerName: "ItemsController", Request: itemBody{}, Response: item{}},
+		{Pattern: "/api/items/{id}", Method: http.MethodDelete, Name: "DeleteItem", ControllerName: "ItemsController", Request: itemParams{}},
+		{Pattern: "/api/files/{path...}", Method: http.MethodGet, Name: "ListFiles", ControllerName: "FilesController", Request: listParams{}},
+		{Pattern: "/api/health", Method: http.MethodGet, Name: "Health", ControllerName: "HealthController", Status: http.StatusAccepted},
+	}
+	isPublic := func(path string) bool { return path == "/api/health" }
+
+	doc := Generate("test", "1.0.0", routes, isPublic)
+
+	type param struct {
+		name     string
+		in       string
+		typ      string
+		required bool
+	}
+
+	testCases := []struct {
+		name        string
+		path        string
+		method      string
+		operationID string
+		tag         string
+		params      []param
+		body        bool
+		status      string
+		public      bool
+	}{
+		{
+			name:        "Path and query params",
+			path:        "/api/items/{id}",
+			method:      "get",
+			operationID: "GetItem",
+			tag:         "Items",
+			params: []param{
+				{name: "id", in: "path", typ: "integer", required: true},
+				{name: "verbose", in: "query", typ: "boolean"},
+				{name: "fields", in: "query", typ: "string", required: true},
+			},
+			status: "200",
+		},
+		{
+			name:        "Body with a path param",
+			path:        "/api/items/{id}",
+			method:      "put",
+			operationID: "UpdateItem",
+			tag:         "Items",
+			params:      []param{{name: "id", in: "path", typ: "integer", required: true}},
+			body:        true,
+			status:      "200",
+		},
+		{
+			name:        "No response",
+			path:        "/api/items/{id}",
+			method:      "delete",
+			operationID: "DeleteItem",
+			tag:         "Items",
+			params: []param{
+				{name: "id", in: "path", typ: "integer", required: true},
+				{name: "verbose", in: "query", typ: "boolean"},
+				{name: "fields", in: "query", typ: "string", required: true},
+			},
+			status: "204",
+		},
+		{
+			name:        "Wildcard and embedded params",
+			path:        "/api/files/{path}",
+			method:      "get",
+			operationID: "ListFiles",
+			tag:         "Files",
+			params: []param{
+				{name: "path", in: "path", typ: "string", required: true},
+				{name: "limit", in: "query", typ: "integer"},
+			},
+			status: "204",
+		},
+		{
+			name:        "Public route with a custom status",
+			path:        "/api/health",
+			method:      "get",
+			operationID: "Health",
+			tag:         "Health",
+			status:      "202",
+			public:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			op, ok := doc.Paths[tc.path][tc.method]
+			if !ok {
+				t.Fatalf("Expected %v %v to be documented, but got paths %v", tc.method, tc.path, doc.Paths)
+			}
+
+			if op.OperationID != tc.operationID {
+				t.Errorf("Expected operation id %v, but got %v", tc.operationID, op.OperationID)
+			}
+			if !slices.Equal(op.Tags, []string{tc.tag}) {
+				t.Errorf("Expected tags [%v], but got %v", tc.tag, op.Tags)
+			}
+
+			params := []param{}
+			for _, p := range op.Parameters {
+				params = append(params, param{name: p.Name, in: p.In, typ: p.Schema.Type, required: p.Required})
+			}
+			if !slices.Equal(params, tc.params) {
+				t.Errorf("Parameters mismatch: got %+v, want %+v", params, tc.params)
+			}
+
+			if (op.RequestBody != nil) != tc.body {
+				t.Errorf("Expected request body %v, but got %+v", tc.body, op.RequestBody)
+			}
+
+			if _, ok := op.Responses[tc.status]; !ok {
+				t.Errorf("Expected a %v response, but got %v", tc.status, op.Responses)
+			}
+			if _, ok := op.Responses["default"]; !ok {
+				t.Errorf("Expected a default error response")
+			}
+
+			public := len(op.Security) == 1 && len(op.Security[0]) == 0
+			if public != tc.public {
+				t.Errorf("Expected public %v, but got security %v", tc.public, op.Security)
+			}
+		})
+	}
+
+	schemas := slices.Collect(maps.Keys(doc.Components.Schemas))
+	for _, schema := range []string{"Item", "ItemBody", "ErrorBody"} {
+		if !slices.Contains(schemas, schema) {
+			t.Errorf("Expected a %v schema, but got %v", schema, schemas)
+		}
+	}
+}
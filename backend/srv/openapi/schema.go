@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schema returns the schema for t. named structs are added to the components and referenced
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schema(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.register(t)}
+	}
+
+	// interfaces and anything else can hold any value
+	return &Schema{}
+}
+
+func (g *generator) register(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := exportedName(t.Name())
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = exportedName(pkg) + name
+	}
+
+	g.names[t] = name
+	// reserve the name before recursing so self referencing types terminate
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.structSchema(t)
+
+	return name
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for _, field := range jsonFields(t) {
+		schema.Properties[field.name] = g.schema(field.typ)
+		if field.required {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+
+	return schema
+}
+
+// parameters lists the path and query parameters bind.Into fills t from. the json tag doesn't matter
+// here, those fields are usually tagged json:"-" so they stay out of the body
+func (g *generator) parameters(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	params := []Parameter{}
+	for _, field := range boundFields(t) {
+		params = append(params, Parameter{Name: field.name, In: field.in, Required: field.required || field.in == "path", Schema: g.schema(field.typ)})
+	}
+	return params
+}
+
+type boundField struct {
+	name     string
+	in       string
+	typ      reflect.Type
+	required bool
+}
+
+// boundFields lists the fields tagged with path or query, flattening embedded structs like bind.Into does
+func boundFields(t reflect.Type) []boundField {
+	fields := []boundField{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, boundFields(embedded)...)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		for _, in := range []string{"path", "query"} {
+			name := field.Tag.Get(in)
+			if name == "" {
+				continue
+			}
+			fields = append(fields, boundField{
+				name:     name,
+				in:       in,
+				typ:      field.Type,
+				required: slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required"),
+			})
+		}
+	}
+
+	return fields
+}
+
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+}
+
+// jsonFields lists the fields the way encoding/json would encode them, flattening embedded structs
+func jsonFields(t reflect.Type) []jsonField {
+	fields := []jsonField{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, jsonField{
+			name:     name,
+			typ:      field.Type,
+			required: slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required"),
+		})
+	}
+
+	return fields
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -1,8 +1,13 @@
 package srv
 
 import (
+	"encoding/json"
 	"fmt"
+	"golang-web-core/repositories"
+	"golang-web-core/srv/cfg"
 	"golang-web-core/util"
+	"golang-web-core/util/logging"
+	"os"
 	"strings"
 )
 
@@ -18,6 +23,9 @@ func newLine(label string, value any, indent int, valueColor string) configLine
 }
 
 func (l configLine) String() string {
+	if !logging.IsTerminal(os.Stdout) {
+		return fmt.Sprintf("%v[%v]: %v", strings.Repeat("   ", l.indent), l.label, l.value)
+	}
 	return fmt.Sprintf("%v[%v]: %v", strings.Repeat("   ", l.indent), util.WrapItalicColor("lightgray", "%v", l.label), util.WrapColor(l.valueColor, "%v", l.value))
 }
 
@@ -30,7 +38,15 @@ func PrintServerConfig(server *Server) {
 
 	printLine(0, "Server Config", "", "")
 	printLine(1, "Environment", c.Env, "brown")
-	printLine(1, "Port", c.Port, "lightgreen")
+	if c.DisableTCP {
+		printLine(1, "TCP", "disabled", "lightred")
+	} else {
+		printLine(1, "Port", c.Port, "lightgreen")
+		printLine(1, "Hosts", strings.Join(c.Hosts, ", "), "lightgreen")
+	}
+	if c.UnixSocket.Enabled {
+		printLine(1, "Unix Socket", c.UnixSocket.Path, "lightgreen")
+	}
 	printLine(1, "Public FS Enabled", c.PublicFS, "lightblue")
 	printLine(1, "Using SSL", c.IsSSL(), "lightblue")
 	if c.IsSSL() {
@@ -38,7 +54,37 @@ func PrintServerConfig(server *Server) {
 		printLine(2, "Key Path", c.SSL.KeyPath, "")
 	}
 	printLine(1, "Number of Routes", len(server.Routes), "lightgreen")
-	printLine(0, "App Repository", c.AppRepository.Type, "brown")
-	printLine(0, "File Association Repository", c.FileAssociationRepository.Type, "brown")
+	printLine(1, "Auth Token Path", c.AuthTokenPath, "")
+	for _, token := range c.AuthTokens {
+		printLine(2, token.Name, fmt.Sprintf("%v (%v)", token.Path, strings.Join(token.Scopes, ", ")), "")
+	}
+	if c.ReadOnly {
+		printLine(1, "Read Only", c.ReadOnly, "lightred")
+	}
+	printLine(1, "Timeouts", fmt.Sprintf("read header %vs, read %vs, write %vs, idle %vs, shutdown %vs", c.Timeouts.ReadHeaderSeconds, c.Timeouts.ReadSeconds, c.Timeouts.WriteSeconds, c.Timeouts.IdleSeconds, c.Timeouts.ShutdownSeconds), "lightblue")
+	printLine(1, "Max Body Size (MB)", c.Limits.MaxBodyMB, "lightblue")
+	if c.Limits.RequestsPerSecond > 0 {
+		printLine(1, "Rate Limit", fmt.Sprintf("%v/s, burst %v", c.Limits.RequestsPerSecond, c.Limits.Burst), "lightblue")
+	}
+	printLine(1, "CORS Allowed Origins", strings.Join(c.CORS.AllowedOrigins, ", "), "lightblue")
+	printLine(1, "Allowed Roots", strings.Join(c.AllowedRoots, ", "), "lightblue")
+	if len(c.DeniedPaths) > 0 {
+		printLine(1, "Denied Paths", strings.Join(c.DeniedPaths, ", "), "lightred")
+	}
+	printRepository("App Repository", c.AppRepository, repositories.Apps.Describe)
+	printRepository("File Association Repository", c.FileAssociationRepository, repositories.FileAssociations.Describe)
+	printRepository("Share Link Repository", c.ShareLinkRepository, repositories.ShareLinks.Describe)
 	fmt.Println("")
 }
+
+// printRepository prints the type of a repository and its settings with secrets redacted
+func printRepository(label string, repository cfg.RepositoryConfig, describe func(string, json.RawMessage) (string, error)) {
+	printLine(0, label, repository.Type, "brown")
+
+	settings, err := describe(repository.Type, repository.Config)
+	if err != nil {
+		printLine(1, "Config", err, "lightred")
+	} else if settings != "" {
+		printLine(1, "Config", settings, "brown")
+	}
+}
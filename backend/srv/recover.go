@@ -0,0 +1,59 @@
+package srv
+
+import (
+	"fmt"
+	"golang-web-core/srv/srverr"
+	"golang-web-core/util/metrics"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// serveRecovered calls handler and turns a panic into a logged 500 instead of a dropped connection.
+// it reports aborted when the response had already been started, the caller must then panic with
+// http.ErrAbortHandler so the client notices the response is incomplete
+func serveRecovered(rw *responseRecorder, req *http.Request, pattern string, handler http.HandlerFunc) (aborted bool) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		if recovered == http.ErrAbortHandler {
+			aborted = true
+			return
+		}
+
+		metrics.HandlerPanics.With(req.Method, pattern).Inc()
+		slog.ErrorContext(req.Context(), "panic while handling request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"panic", fmt.Sprint(recovered),
+			"stack", string(debug.Stack()),
+		)
+
+		if rw.WroteHeader() {
+			aborted = true
+			return
+		}
+
+		srvErr := srverr.New("internal server error", http.StatusInternalServerError)
+		if isMutatingMethod(req.Method) {
+			srvErr.Detail = "the operation was interrupted and may have been partially applied, check the affected files before retrying"
+		}
+		srverr.HandleSrvError(rw, srvErr)
+	}()
+
+	handler(rw, req)
+	return false
+}
+
+// recoverHandler protects handlers that are not registered through HandleRequest, like the share server
+func recoverHandler(pattern string, handler http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		rw := newResponseRecorder(w)
+
+		if serveRecovered(rw, req, pattern, handler.ServeHTTP) {
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
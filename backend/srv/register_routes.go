@@ -1,18 +1,38 @@
 package srv
 
 import (
+	"encoding/json"
 	"fmt"
 	"golang-web-core/controllers"
+	"golang-web-core/srv/openapi"
+	"golang-web-core/srv/route"
 	"net/http"
 	"slices"
 )
 
-func (s *Server) RegisterRoutes() error {
-	appController, err := controllers.NewApplicationController(s.Config)
+// RegisterRoutes sets up the application controller and registers its routes. previous is the application
+// controller of the config that is being reloaded, if any. inspect sets it up without creating the api tokens
+func (s *Server) RegisterRoutes(previous *controllers.ApplicationController, inspect bool) error {
+	var appController controllers.ApplicationController
+	var err error
+	if inspect {
+		appController, err = controllers.InspectApplicationController(s.Config)
+	} else {
+		appController, err = controllers.NewApplicationController(s.Config, previous)
+	}
 	if err != nil {
 		return err
 	}
+	s.App = appController
 	routes := s.Router.Routes(appController)
+	routes = append(routes, route.Route{
+		Pattern:        "/api/openapi.json",
+		Method:         http.MethodGet,
+		Handler:        s.ServeOpenAPI,
+		ControllerName: appController.Name(),
+		Summary:        "Get the OpenAPI document describing this api",
+		Response:       openapi.Document{},
+	})
 
 	registeredPatterns := []string{}
 
@@ -26,15 +46,28 @@ func (s *Server) RegisterRoutes() error {
 		s.Mux.HandleFunc(fmt.Sprintf("%v %v", route.Method, route.Pattern), HandleRequest(appController, route))
 		patternRegistered := slices.Contains(registeredPatterns, route.Pattern)
 		if !patternRegistered {
-			s.Mux.HandleFunc(fmt.Sprintf("%v %v", http.MethodOptions, route.Pattern), http.HandlerFunc(HandleOptions))
+			s.Mux.HandleFunc(fmt.Sprintf("%v %v", http.MethodOptions, route.Pattern), http.HandlerFunc(s.HandleOptions))
 		}
 
 		registeredPatterns = append(registeredPatterns, route.Pattern)
 	}
 
+	s.OpenAPI, err = json.Marshal(openapi.Generate("Linux File Explorer", Version, routes, controllers.IsPublicPath))
+	if err != nil {
+		return err
+	}
+
+	shareLinks := appController.GetController("ShareLinksController").(controllers.ShareLinksController)
+	s.Mux.Handle("GET /share/{token}", recoverHandler("/share/{token}", ShareServer{Prefix: "/share/", Links: shareLinks, Sandbox: appController.Sandbox}))
+
 	if s.Config.PublicFS {
 		s.Mux.Handle("GET /public/", http.StripPrefix("/public/", FileServer{Prefix: "/public/", Handler: http.FileServer(http.Dir("public"))}))
 	}
 
 	return nil
 }
+
+func (s *Server) ServeOpenAPI(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Write(s.OpenAPI)
+}
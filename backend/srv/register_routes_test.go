@@ -0,0 +1,95 @@
+package srv
+
+import (
+	"encoding/json"
+	"golang-web-core/controllers"
+	"golang-web-core/srv/openapi"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	server, err := InspectServer(testConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc openapi.Document
+	err = json.Unmarshal(server.OpenAPI, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pathParam := regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+	for key, r := range server.Routes {
+		t.Run(key, func(t *testing.T) {
+			op, ok := doc.Paths[pathParam.ReplaceAllString(r.Pattern, "{$1}")][strings.ToLower(r.Method)]
+			if !ok {
+				t.Fatalf("Expected the route to be documented")
+			}
+
+			expected := []openapi.Parameter{}
+			for _, match := range pathParam.FindAllStringSubmatch(r.Pattern, -1) {
+				expected = append(expected, openapi.Parameter{Name: match[1], In: "path"})
+			}
+			if r.Request != nil {
+				for _, name := range queryParams(reflect.TypeOf(r.Request)) {
+					expected = append(expected, openapi.Parameter{Name: name, In: "query"})
+				}
+			}
+
+			for _, param := range expected {
+				if !slices.ContainsFunc(op.Parameters, func(p openapi.Parameter) bool { return p.Name == param.Name && p.In == param.In }) {
+					t.Errorf("Expected the %v param %v to be documented, but got %+v", param.In, param.Name, op.Parameters)
+				}
+			}
+			if len(op.Parameters) != len(expected) {
+				t.Errorf("Expected %v params, but got %+v", len(expected), op.Parameters)
+			}
+		})
+	}
+}
+
+// queryParams lists the query tags of t and the structs embedded in it
+func queryParams(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			names = append(names, queryParams(field.Type)...)
+			continue
+		}
+		if name := field.Tag.Get("query"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestEveryControllerHasRoutes(t *testing.T) {
+	server, err := InspectServer(testConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, controller := range server.App.Controllers {
+		if name == server.App.Name() {
+			// the router registers the application controller's routes itself
+			continue
+		}
+		if _, ok := controller.(controllers.RouteProvider); !ok {
+			t.Errorf("Expected %v to provide routes, controllers without routes should not be registered", name)
+		}
+	}
+}
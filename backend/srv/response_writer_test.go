@@ -0,0 +1,129 @@
+package srv
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	testCases := []struct {
+		name        string
+		handler     func(rw http.ResponseWriter)
+		wantStatus  int
+		wantBytes   int64
+		wantFlushed bool
+	}{
+		{
+			name:       "Nothing written",
+			handler:    func(rw http.ResponseWriter) {},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Status and body",
+			handler: func(rw http.ResponseWriter) {
+				rw.WriteHeader(http.StatusNotFound)
+				rw.Write([]byte("missing"))
+			},
+			wantStatus: http.StatusNotFound,
+			wantBytes:  7,
+		},
+		{
+			name: "First status wins",
+			handler: func(rw http.ResponseWriter) {
+				rw.WriteHeader(http.StatusCreated)
+				rw.WriteHeader(http.StatusInternalServerError)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "Several writes",
+			handler: func(rw http.ResponseWriter) {
+				rw.Write([]byte("hello "))
+				rw.Write([]byte("world"))
+			},
+			wantStatus: http.StatusOK,
+			wantBytes:  11,
+		},
+		{
+			name: "Read from",
+			handler: func(rw http.ResponseWriter) {
+				rw.(io.ReaderFrom).ReadFrom(strings.NewReader("streamed"))
+			},
+			wantStatus: http.StatusOK,
+			wantBytes:  8,
+		},
+		{
+			name: "Flush through a response controller",
+			handler: func(rw http.ResponseWriter) {
+				rw.Write([]byte("chunk"))
+				if err := http.NewResponseController(rw).Flush(); err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+				}
+			},
+			wantStatus:  http.StatusOK,
+			wantBytes:   5,
+			wantFlushed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			recorder := newResponseRecorder(rw)
+
+			tc.handler(recorder)
+
+			if recorder.Status() != tc.wantStatus {
+				t.Errorf("Expected status %v, but got %v", tc.wantStatus, recorder.Status())
+			}
+			if recorder.Bytes() != tc.wantBytes {
+				t.Errorf("Expected %v bytes, but got %v", tc.wantBytes, recorder.Bytes())
+			}
+			if int64(rw.Body.Len()) != tc.wantBytes {
+				t.Errorf("Expected %v bytes in the body, but got %v", tc.wantBytes, rw.Body.Len())
+			}
+			if rw.Flushed != tc.wantFlushed {
+				t.Errorf("Expected flushed %v, but got %v", tc.wantFlushed, rw.Flushed)
+			}
+		})
+	}
+}
+
+func TestResponseRecorderHijack(t *testing.T) {
+	recorders := make(chan *responseRecorder, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		recorder := newResponseRecorder(rw)
+		recorders <- recorder
+
+		conn, buf, err := http.NewResponseController(recorder).Hijack()
+		if err != nil {
+			t.Errorf("Expected no error, but got: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		buf.Flush()
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != "hijacked" {
+		t.Errorf("Expected the body written to the hijacked connection, but got %q", body)
+	}
+	if recorder := <-recorders; recorder.Status() != http.StatusSwitchingProtocols {
+		t.Errorf("Expected status %v, but got %v", http.StatusSwitchingProtocols, recorder.Status())
+	}
+}
@@ -0,0 +1,26 @@
+package route
+
+import "strings"
+
+// Group prepends prefix to the pattern of every route. a pattern of "" maps to the prefix itself
+func Group(prefix string, routes ...Route) []Route {
+	grouped := make([]Route, 0, len(routes))
+
+	for _, r := range routes {
+		r.Pattern = JoinPattern(prefix, r.Pattern)
+		grouped = append(grouped, r)
+	}
+
+	return grouped
+}
+
+func JoinPattern(prefix, pattern string) string {
+	if prefix == "" {
+		return pattern
+	}
+	if pattern == "" {
+		return prefix
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(pattern, "/")
+}
@@ -0,0 +1,18 @@
+package route
+
+import "net/http"
+
+type Middleware func(next http.HandlerFunc) http.HandlerFunc
+
+// Chain wraps handler in the middlewares. the first middleware is the outermost one and runs first
+func Chain(handler http.HandlerFunc, middlewares ...Middleware) http.HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// WithMiddlewares wraps handler in the middlewares of the route
+func (r Route) WithMiddlewares(handler http.HandlerFunc) http.HandlerFunc {
+	return Chain(handler, r.Middlewares...)
+}
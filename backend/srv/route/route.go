@@ -9,4 +9,23 @@ type Route struct {
 	Method         string
 	Handler        http.HandlerFunc
 	ControllerName string
+	// Name is the operationId in the openapi document. defaults to the name of the handler method, set it for
+	// handlers wrapped in Typed
+	Name string
+	// Middlewares run after the global BeforeAction and before the params are parsed, in order
+	Middlewares []Middleware
+	// ContextParams parses the query string or body into a map before the handler runs, for handlers that read
+	// it with util.GetParamsFromContext. handlers wrapped in Typed bind their own params and leave it unset
+	ContextParams bool
+
+	// the fields below are only used to generate the openapi document and are all optional
+
+	// Summary is a short description of what the route does
+	Summary string
+	// Request is a zero value of the type the params are decoded into, e.g. domain.FileAssociation{}
+	Request any
+	// Response is a zero value of the type that is encoded into the response body, e.g. []domain.App{}
+	Response any
+	// Status is the status code returned on success. defaults to 200, or 204 when there is no Response
+	Status int
 }
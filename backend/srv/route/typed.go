@@ -0,0 +1,55 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"golang-web-core/srv/srverr"
+	"golang-web-core/util/bind"
+	"log/slog"
+	"net/http"
+)
+
+// Empty is used as the request type of handlers without params and as the response type of handlers
+// that respond without a body
+type Empty struct{}
+
+// Typed adapts a handler that works on typed values to an http.HandlerFunc. Req is bound from the request and
+// validated with bind.Into, the returned Resp is encoded as json with the given status (200 by default, 204 for
+// Empty). errors are mapped to status codes by srverr.FromError
+func Typed[Req, Resp any](handler func(ctx context.Context, req Req) (Resp, error), status ...int) http.HandlerFunc {
+	_, isEmpty := any(*new(Resp)).(Empty)
+
+	successStatus := http.StatusOK
+	if len(status) > 0 {
+		successStatus = status[0]
+	} else if isEmpty {
+		successStatus = http.StatusNoContent
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		params, err := bind.Request[Req](req)
+		if err != nil {
+			srverr.HandleBindError(rw, err)
+			return
+		}
+
+		resp, err := handler(req.Context(), params)
+		if err != nil {
+			srverr.HandleSrvError(rw, err)
+			return
+		}
+
+		if isEmpty {
+			rw.WriteHeader(successStatus)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(successStatus)
+		err = json.NewEncoder(rw).Encode(resp)
+		if err != nil {
+			// the status was already sent, all that is left is to log it
+			slog.WarnContext(req.Context(), "could not encode the response", "error", err)
+		}
+	}
+}
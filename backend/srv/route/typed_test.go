@@ -0,0 +1,144 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"golang-web-core/srv/srverr"
+	"golang-web-core/util/bind"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+type renameRequest struct {
+	ID   int    `path:"id" json:"-"`
+	Name string `json:"name" validate:"required"`
+}
+
+type renameResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestTyped(t *testing.T) {
+	rename := func(ctx context.Context, req renameRequest) (renameResponse, error) {
+		if req.Name == "missing" {
+			return renameResponse{}, &fs.PathError{Op: "rename", Path: "/home/user/missing", Err: syscall.ENOENT}
+		}
+		return renameResponse{ID: req.ID, Name: req.Name}, nil
+	}
+	remove := func(ctx context.Context, req renameRequest) (Empty, error) {
+		return Empty{}, nil
+	}
+
+	testCases := []struct {
+		name        string
+		handler     http.HandlerFunc
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    *renameResponse
+		wantFields  []bind.FieldError
+	}{
+		{
+			name:        "Bound and encoded",
+			handler:     Typed(rename),
+			contentType: "application/json",
+			body:        `{"name":"notes"}`,
+			wantStatus:  http.StatusOK,
+			wantBody:    &renameResponse{ID: 7, Name: "notes"},
+		},
+		{
+			name:        "Custom status",
+			handler:     Typed(rename, http.StatusCreated),
+			contentType: "application/json",
+			body:        `{"name":"notes"}`,
+			wantStatus:  http.StatusCreated,
+			wantBody:    &renameResponse{ID: 7, Name: "notes"},
+		},
+		{
+			name:        "Empty response",
+			handler:     Typed(remove),
+			contentType: "application/json",
+			body:        `{"name":"notes"}`,
+			wantStatus:  http.StatusNoContent,
+		},
+		{
+			name:        "Validation error",
+			handler:     Typed(rename),
+			contentType: "application/json",
+			body:        `{}`,
+			wantStatus:  http.StatusBadRequest,
+			wantFields:  []bind.FieldError{{Field: "name", Source: bind.SourceBody, Message: "is required"}},
+		},
+		{
+			name:        "Invalid json",
+			handler:     Typed(rename),
+			contentType: "application/json",
+			body:        `{"name":`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "Unsupported content type",
+			handler:     Typed(rename),
+			contentType: "text/plain",
+			body:        `{"name":"notes"}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "Handler error",
+			handler:     Typed(rename),
+			contentType: "application/json",
+			body:        `{"name":"missing"}`,
+			wantStatus:  http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("PUT /items/{id}", tc.handler)
+
+			req := httptest.NewRequest(http.MethodPut, "/items/7", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, req)
+
+			if rw.Code != tc.wantStatus {
+				t.Fatalf("Expected status %v, but got %v: %v", tc.wantStatus, rw.Code, rw.Body.String())
+			}
+
+			switch {
+			case tc.wantBody != nil:
+				var body renameResponse
+				err := json.NewDecoder(rw.Body).Decode(&body)
+				if err != nil {
+					t.Fatalf("Expected a json body, but got: %v", err)
+				}
+				if body != *tc.wantBody {
+					t.Errorf("Expected body %+v, but got %+v", *tc.wantBody, body)
+				}
+			case rw.Code >= http.StatusBadRequest:
+				var body srverr.ErrorBody
+				err := json.NewDecoder(rw.Body).Decode(&body)
+				if err != nil {
+					t.Fatalf("Expected a json error, but got: %v", err)
+				}
+				if body.Error.Code != tc.wantStatus {
+					t.Errorf("Expected error code %v, but got %v", tc.wantStatus, body.Error.Code)
+				}
+				if tc.wantFields != nil && !slices.Equal(body.Error.Fields, tc.wantFields) {
+					t.Errorf("Expected fields %+v, but got %+v", tc.wantFields, body.Error.Fields)
+				}
+			default:
+				if rw.Body.Len() != 0 {
+					t.Errorf("Expected an empty body, but got %q", rw.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -1,58 +1,263 @@
 package srv
 
 import (
+	"errors"
 	"fmt"
+	"golang-web-core/controllers"
 	"golang-web-core/routes"
 	"golang-web-core/srv/cfg"
+	"golang-web-core/srv/instance"
 	"golang-web-core/srv/route"
-	"golang-web-core/util"
+	"golang-web-core/util/systemd"
+	"log/slog"
 	"net"
 	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 )
 
 type Server struct {
-	Config cfg.Config
-	Router routes.Router
-	Mux    http.ServeMux
-	Routes map[string]route.Route
+	Config  cfg.Config
+	Router  routes.Router
+	Mux     *http.ServeMux
+	Routes  map[string]route.Route
+	OpenAPI []byte
+	App     controllers.ApplicationController
+	// LoadConfig reads the config again when the server receives SIGHUP. reloading is disabled when it is nil
+	LoadConfig func() (cfg.Config, error)
+	// Lock is the single-instance lock. Start publishes the discovery file to it once it is listening
+	Lock *instance.Lock
+
+	// mu guards the fields above and the handler, which are replaced when the config is reloaded
+	mu      sync.RWMutex
+	handler http.Handler
+	// shutdownDone receives the result of the graceful shutdown
+	shutdownDone chan error
 }
 
 func NewServer(c cfg.Config) (*Server, error) {
-	server := Server{
+	return newServer(c, false)
+}
+
+// InspectServer sets up the routes for c like NewServer, but without side effects like creating the api tokens.
+// it is for commands that describe or validate the config and must not be started
+func InspectServer(c cfg.Config) (*Server, error) {
+	return newServer(c, true)
+}
+
+func newServer(c cfg.Config, inspect bool) (*Server, error) {
+	built, err := buildServer(c, nil, inspect)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{shutdownDone: make(chan error, 1)}
+	server.use(built)
+
+	return server, nil
+}
+
+// buildServer sets up the controllers and routes for c. the server it returns is never modified afterwards,
+// so the handlers bound to it keep working with the same config until they are replaced by a reload
+func buildServer(c cfg.Config, previous *controllers.ApplicationController, inspect bool) (*Server, error) {
+	server := &Server{
 		Config: c,
 		Router: routes.NewRouter(c),
-		Mux:    *http.NewServeMux(),
+		Mux:    http.NewServeMux(),
 		Routes: map[string]route.Route{},
 	}
 
-	err := server.RegisterRoutes()
+	err := server.RegisterRoutes(previous, inspect)
 	if err != nil {
 		return nil, err
 	}
 
-	PrintServerConfig(&server)
+	return server, nil
+}
 
-	return &server, nil
+// use switches s over to the config, controllers and routes of built
+func (s *Server) use(built *Server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Config = built.Config
+	s.Router = built.Router
+	s.Mux = built.Mux
+	s.Routes = built.Routes
+	s.OpenAPI = built.OpenAPI
+	s.App = built.App
+	s.handler = built.withCORS(built.Mux)
+}
+
+func (s *Server) config() cfg.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Config
+}
+
+func (s *Server) app() controllers.ApplicationController {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.App
+}
+
+// ServeHTTP hands the request to the routes of the current config
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mu.RLock()
+	handler := s.handler
+	s.mu.RUnlock()
+
+	handler.ServeHTTP(rw, req)
 }
 
 func (s *Server) Start() error {
-	addr := fmt.Sprintf(":%v", s.Config.Port)
+	// the listeners and timeouts can't change while the server is running, see cfg.NeedsRestart
+	config := s.config()
+
 	server := http.Server{
-		Addr:    addr,
-		Handler: &s.Mux,
+		Handler:           s,
+		ReadHeaderTimeout: config.Timeouts.ReadHeader(),
+		ReadTimeout:       config.Timeouts.Read(),
+		WriteTimeout:      config.Timeouts.Write(),
+		IdleTimeout:       config.Timeouts.Idle(),
 	}
 
-	l, err := net.Listen("tcp", addr)
+	// when systemd passes sockets they replace the configured port and unix socket
+	listeners, err := systemd.Listeners()
 	if err != nil {
 		return err
 	}
+	if len(listeners) > 0 {
+		slog.Info("using the sockets passed by systemd, port and unixSocket are ignored", "count", len(listeners))
+		listeners = restrictUnixListeners(listeners)
+	} else {
+		listeners, err = listen(config)
+		if err != nil {
+			return err
+		}
+	}
 
 	s.RegisterHandleShutdown(&server)
+	s.RegisterHandleReload()
+
+	if s.Lock != nil {
+		err = s.Lock.Publish(discovery(config, listeners))
+		if err != nil {
+			slog.Warn("could not write the discovery file, clients won't be able to find the server", "error", err)
+		}
+	}
 
-	util.LogColor("lightgreen", "Server listening on %v\n", addr)
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		slog.Info("server listening", "network", l.Addr().Network(), "address", l.Addr().String())
 
-	if s.Config.IsSSL() {
-		return server.ServeTLS(l, s.Config.SSL.CertPath, s.Config.SSL.KeyPath)
+		go func() {
+			if config.IsSSL() {
+				errs <- server.ServeTLS(l, config.SSL.CertPath, config.SSL.KeyPath)
+				return
+			}
+			errs <- server.Serve(l)
+		}()
+	}
+
+	notifySystemd("READY=1")
+	systemd.StartWatchdog()
+
+	err = <-errs
+	if errors.Is(err, http.ErrServerClosed) {
+		// Serve returns as soon as shutdown starts, so wait for the requests and jobs to finish
+		return <-s.shutdownDone
+	}
+
+	// a listener failed. the others are shut down with it instead of leaving the server reachable on some of them
+	slog.Error("a listener failed, shutting down", "error", err)
+	return errors.Join(err, s.Shutdown(&server))
+}
+
+// discovery describes where the listeners can be reached
+func discovery(config cfg.Config, listeners []net.Listener) instance.Discovery {
+	d := instance.Discovery{
+		PID:       os.Getpid(),
+		TokenPath: config.AuthTokenPath,
+		Version:   Version,
+		StartedAt: time.Now(),
+	}
+
+	for _, l := range listeners {
+		switch addr := l.Addr().(type) {
+		case *net.TCPAddr:
+			scheme := "http"
+			if config.IsSSL() {
+				scheme = "https"
+			}
+			if d.URL != "" {
+				continue
+			}
+			host := "localhost"
+			if !addr.IP.IsLoopback() && !addr.IP.IsUnspecified() {
+				host = addr.IP.String()
+			}
+			d.Port = addr.Port
+			d.URL = fmt.Sprintf("%v://%v", scheme, net.JoinHostPort(host, strconv.Itoa(addr.Port)))
+		case *net.UnixAddr:
+			d.UnixSocket = addr.Name
+		}
+	}
+
+	return d
+}
+
+func listen(config cfg.Config) ([]net.Listener, error) {
+	listeners := []net.Listener{}
+
+	closeAll := func() {
+		for _, open := range listeners {
+			open.Close()
+		}
+	}
+
+	if !config.DisableTCP {
+		for _, host := range config.Hosts {
+			l, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(config.Port)))
+			if err != nil && isDefaultIPv6Loopback(config, host, err) {
+				slog.Debug("ipv6 is not available, not listening on ::1", "error", err)
+				continue
+			}
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			listeners = append(listeners, l)
+		}
+	}
+
+	if config.UnixSocket.Enabled {
+		l, err := listenUnix(config.UnixSocket.Path)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+
+	return listeners, nil
+}
+
+// isDefaultIPv6Loopback reports whether err is ::1 being unavailable on a machine without ipv6 while only the
+// default hosts are used, in which case 127.0.0.1 is enough
+func isDefaultIPv6Loopback(config cfg.Config, host string, err error) bool {
+	return host == "::1" && slices.Equal(config.Hosts, cfg.DefaultHosts) &&
+		(errors.Is(err, syscall.EADDRNOTAVAIL) || errors.Is(err, syscall.EAFNOSUPPORT))
+}
+
+// notifySystemd sends a state to systemd when it supervises the server
+func notifySystemd(state string) {
+	err := systemd.Notify(state)
+	if err != nil {
+		slog.Warn("could not notify systemd", "state", state, "error", err)
 	}
-	return server.Serve(l)
 }
@@ -0,0 +1,103 @@
+package srv
+
+import (
+	"golang-web-core/srv/cfg"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// Helper function to load the default config with every user directory pointing into a temp dir
+func testConfig(t *testing.T) cfg.Config {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("XDG_RUNTIME_DIR", home)
+
+	config, _, err := cfg.Load(filepath.Join("..", "configs", "default.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+// Helper function to find a port nothing is listening on
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestListen(t *testing.T) {
+	testCases := []struct {
+		name          string
+		hosts         []string
+		socketPath    string
+		wantListeners int
+		expectErr     bool
+	}{
+		{
+			name:          "Tcp and unix socket",
+			hosts:         []string{"127.0.0.1"},
+			socketPath:    "lfe.sock",
+			wantListeners: 2,
+		},
+		{
+			name:       "Unix socket fails",
+			hosts:      []string{"127.0.0.1"},
+			socketPath: filepath.Join("missing", "lfe.sock"),
+			expectErr:  true,
+		},
+		{
+			name:      "Second host fails",
+			hosts:     []string{"127.0.0.1", "192.0.2.1"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := cfg.Config{Port: freePort(t), Hosts: tc.hosts}
+			if tc.socketPath != "" {
+				config.UnixSocket = cfg.UnixSocket{Enabled: true, Path: filepath.Join(t.TempDir(), tc.socketPath)}
+			}
+
+			listeners, err := listen(config)
+			for _, l := range listeners {
+				defer l.Close()
+			}
+
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("Expected an error, but got nil")
+				}
+
+				// the listeners opened before the failure have to be closed again
+				l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(config.Port)))
+				if err != nil {
+					t.Errorf("Expected the tcp listener to be closed, but got: %v", err)
+					return
+				}
+				l.Close()
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if len(listeners) != tc.wantListeners {
+				t.Errorf("Expected %v listeners, but got %v", tc.wantListeners, len(listeners))
+			}
+		})
+	}
+}
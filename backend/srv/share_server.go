@@ -0,0 +1,134 @@
+package srv
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"golang-web-core/controllers"
+	"golang-web-core/srv/srverr"
+	"golang-web-core/util"
+	"golang-web-core/util/logging"
+	"golang-web-core/util/sandbox"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type ShareServer struct {
+	Prefix  string
+	Links   controllers.ShareLinksController
+	Sandbox *sandbox.Sandbox
+}
+
+func (s ShareServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	SetRequestID(rw, req)
+	ctx := logging.WithRequestID(req.Context(), req.Header.Get("X-Request-ID"))
+
+	link, err := s.Links.Resolve(req.PathValue("token"))
+	if err != nil {
+		slog.InfoContext(ctx, "rejected share request", "remote_addr", req.RemoteAddr)
+		srverr.HandleSrvError(rw, err)
+		return
+	}
+
+	// shares can be large, so the download may take longer than the write timeout. a client that disconnects
+	// cancels the request context, which stops the download
+	err = http.NewResponseController(rw).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(ctx, "could not clear the write deadline", "error", err)
+	}
+
+	// the sandbox may have changed since the link was created, so the path is checked again here
+	file, err := s.Sandbox.Open(link.Path)
+	if err != nil {
+		srverr.HandleSrvError(rw, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		srverr.Handle500(rw, err)
+		return
+	}
+
+	if info.IsDir() != link.IsDirectory {
+		srverr.Handle404(rw, fmt.Errorf("%v has changed since it was shared", link.Path))
+		return
+	}
+
+	if countsAsDownload(req) {
+		link, err = s.Links.RecordDownload(link)
+		if err != nil {
+			slog.InfoContext(ctx, "rejected share request", "remote_addr", req.RemoteAddr)
+			srverr.HandleSrvError(rw, err)
+			return
+		}
+	}
+
+	slog.InfoContext(ctx, "serving share", "share_id", link.Id, "path", link.Path, "remote_addr", req.RemoteAddr)
+
+	if link.IsDirectory {
+		err = s.serveZippedFolder(req.Context(), rw, link.Path, info.Name())
+		if errors.Is(err, context.Canceled) {
+			slog.InfoContext(ctx, "share download cancelled by the client", "path", link.Path)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to zip shared folder", "path", link.Path, "error", err)
+			// the archive is left without its central directory, aborting makes sure the client does not take
+			// the partial download for a complete zip
+			panic(http.ErrAbortHandler)
+		}
+		return
+	}
+
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Name()))
+	http.ServeContent(rw, req, info.Name(), info.ModTime(), file)
+}
+
+// serveZippedFolder streams a zip archive of every regular file below root. symlinks are not followed
+func (s ShareServer) serveZippedFolder(ctx context.Context, rw http.ResponseWriter, root string, name string) error {
+	rw.Header().Set("Content-Type", "application/zip")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".zip"))
+
+	archive := zip.NewWriter(rw)
+
+	err := s.Sandbox.WalkFiles(ctx, root, func(rel string, file *os.File, info fs.FileInfo) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(name, rel))
+		header.Method = zip.Deflate
+
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		_, err = util.CopyContext(ctx, writer, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// countsAsDownload reports whether the request starts a download. HEAD requests and ranges that resume a download
+// are not counted, so a download that is interrupted and resumed only uses up the link once
+func countsAsDownload(req *http.Request) bool {
+	if req.Method == http.MethodHead {
+		return false
+	}
+
+	byteRange := req.Header.Get("Range")
+	return byteRange == "" || strings.HasPrefix(strings.TrimSpace(byteRange), "bytes=0-")
+}
@@ -1,44 +1,117 @@
 package srverr
 
 import (
-	"golang-web-core/util"
+	"context"
+	"encoding/json"
+	"golang-web-core/util/bind"
+	"golang-web-core/util/logging"
+	"log/slog"
 	"net/http"
+	"strings"
 )
 
+const ContentType = "application/json; charset=utf-8"
+
+type ErrorBody struct {
+	Error ErrorResponse `json:"error"`
+}
+
+type ErrorResponse struct {
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	// Fields lists the request fields that failed validation
+	Fields []bind.FieldError `json:"fields,omitempty"`
+}
+
+// write sends the json error envelope. the request id is read from the response headers set by HandleRequest
+func write(rw http.ResponseWriter, srvErr ServerError) {
+	rw.Header().Set("Content-Type", ContentType)
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(srvErr.Code)
+
+	response := srvErr.Response()
+	response.RequestID = rw.Header().Get("X-Request-ID")
+
+	json.NewEncoder(rw).Encode(ErrorBody{Error: response})
+}
+
+// Response is what clients are told about the error. 5xx errors only get a generic message unless they are
+// Public, the actual error has to be logged instead
+func (e ServerError) Response() ErrorResponse {
+	response := ErrorResponse{
+		Code:    e.Code,
+		Message: e.Message,
+		Path:    e.Path,
+		Detail:  e.Detail,
+		Fields:  e.Fields,
+	}
+	if e.Code >= http.StatusInternalServerError && !e.public {
+		response.Message = strings.ToLower(http.StatusText(e.Code))
+		response.Path = ""
+	}
+	return response
+}
+
+func logError(rw http.ResponseWriter, level slog.Level, msg string, code int, err error) {
+	slog.Log(context.Background(), level, msg, "status", code, "error", err.Error(), logging.RequestIDKey, rw.Header().Get("X-Request-ID"))
+}
+
+func withCode(err error, code int) ServerError {
+	srvErr := FromError(err)
+	srvErr.Code = code
+	return srvErr
+}
+
 func Handle400(rw http.ResponseWriter, err error) {
-	http.Error(rw, err.Error(), http.StatusBadRequest)
-	util.LogColor("yellow", "BAD REQUEST: %v", err.Error())
+	write(rw, withCode(err, http.StatusBadRequest))
+	logError(rw, slog.LevelWarn, "bad request", http.StatusBadRequest, err)
+}
+
+// HandleBindError responds with a 400 that lists the invalid fields of a bind.ValidationError, a 413 for
+// bodies that are too large, a 415 for bodies that are not json or a form, and a plain 400 for anything else
+func HandleBindError(rw http.ResponseWriter, err error) {
+	srvErr := FromError(err)
+	if srvErr.Code == http.StatusInternalServerError {
+		srvErr.Code = http.StatusBadRequest
+	}
+	HandleError(srvErr.Code, rw, srvErr)
 }
 
 func Handle401(rw http.ResponseWriter, err error) {
-	http.Error(rw, err.Error(), http.StatusUnauthorized)
-	util.LogColor("lightred", "UNAUTHORIZED: %v", err.Error())
+	write(rw, withCode(err, http.StatusUnauthorized))
+	logError(rw, slog.LevelWarn, "unauthorized", http.StatusUnauthorized, err)
 }
 
 func Handle403(rw http.ResponseWriter, err error) {
-	http.Error(rw, err.Error(), http.StatusForbidden)
-	util.LogColor("lightred", "FORBIDDEN: %v", err.Error())
+	write(rw, withCode(err, http.StatusForbidden))
+	logError(rw, slog.LevelWarn, "forbidden", http.StatusForbidden, err)
 }
 
 func Handle404(rw http.ResponseWriter, err error) {
-	http.Error(rw, err.Error(), http.StatusNotFound)
-	util.LogColor("yellow", "NOT FOUND: %v", err.Error())
+	write(rw, withCode(err, http.StatusNotFound))
+	logError(rw, slog.LevelWarn, "not found", http.StatusNotFound, err)
 }
 
 func Handle500(rw http.ResponseWriter, err error) {
-	http.Error(rw, err.Error(), http.StatusInternalServerError)
-	util.LogColor("red", "INTERNAL SERVER ERROR: %v", err.Error())
+	write(rw, withCode(err, http.StatusInternalServerError))
+	logError(rw, slog.LevelError, "internal server error", http.StatusInternalServerError, err)
 }
 
 func HandleError(code int, rw http.ResponseWriter, err error) {
-	http.Error(rw, err.Error(), code)
-	util.LogColor("red", "%v: %v", code, err.Error())
+	write(rw, withCode(err, code))
+
+	level := slog.LevelWarn
+	if code >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	logError(rw, level, strings.ToLower(http.StatusText(code)), code, err)
 }
 
+// HandleSrvError responds with the code of a ServerError, or the code mapped from a wrapped os error
 func HandleSrvError(rw http.ResponseWriter, err error) {
-	if srvErr, ok := err.(ServerError); ok {
-		HandleError(srvErr.Code, rw, err)
-	} else {
-		Handle500(rw, err)
-	}
+	srvErr := FromError(err)
+	HandleError(srvErr.Code, rw, srvErr)
 }
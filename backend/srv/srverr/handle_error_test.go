@@ -0,0 +1,74 @@
+package srverr
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestHandleSrvError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		err         error
+		wantCode    int
+		wantMessage string
+		wantPath    string
+	}{
+		{
+			name:        "Client errors keep their message",
+			err:         &fs.PathError{Op: "open", Path: "/home/user/missing", Err: syscall.ENOENT},
+			wantCode:    http.StatusNotFound,
+			wantMessage: "open /home/user/missing: no such file or directory",
+			wantPath:    "/home/user/missing",
+		},
+		{
+			name:        "Internal errors get a generic message",
+			err:         &fs.PathError{Op: "read", Path: "/home/user/.cache/db", Err: syscall.EIO},
+			wantCode:    http.StatusInternalServerError,
+			wantMessage: "internal server error",
+		},
+		{
+			name:        "Other 5xx codes get a generic message",
+			err:         &fs.PathError{Op: "write", Path: "/home/user/file", Err: syscall.ENOSPC},
+			wantCode:    http.StatusInsufficientStorage,
+			wantMessage: "insufficient storage",
+		},
+		{
+			name:        "Public 5xx errors keep their message",
+			err:         Public(errors.New("the server is shutting down"), http.StatusServiceUnavailable),
+			wantCode:    http.StatusServiceUnavailable,
+			wantMessage: "the server is shutting down",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			HandleSrvError(rw, tc.err)
+
+			if rw.Code != tc.wantCode {
+				t.Errorf("Expected status %v, but got %v", tc.wantCode, rw.Code)
+			}
+
+			body := ErrorBody{}
+			err := json.Unmarshal(rw.Body.Bytes(), &body)
+			if err != nil {
+				t.Fatalf("Expected a json error body, but got: %v", err)
+			}
+			if body.Error.Message != tc.wantMessage {
+				t.Errorf("Expected message %q, but got %q", tc.wantMessage, body.Error.Message)
+			}
+			if body.Error.Path != tc.wantPath {
+				t.Errorf("Expected path %q, but got %q", tc.wantPath, body.Error.Path)
+			}
+			if tc.wantCode >= http.StatusInternalServerError && strings.Contains(rw.Body.String(), "/home/user") {
+				t.Errorf("Expected the response not to contain the path, but got %v", rw.Body.String())
+			}
+		})
+	}
+}
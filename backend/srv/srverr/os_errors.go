@@ -0,0 +1,62 @@
+package srverr
+
+import (
+	"errors"
+	"fmt"
+	"golang-web-core/util/bind"
+	"golang-web-core/util/sandbox"
+	"io/fs"
+	"net/http"
+	"syscall"
+)
+
+// FromError converts err into a ServerError. ServerErrors are returned as is, wrapped os errors are mapped
+// to the status code a client can act on and everything else becomes a 500
+func FromError(err error) ServerError {
+	var srvErr ServerError
+	if errors.As(err, &srvErr) {
+		return srvErr
+	}
+
+	var validationErr *bind.ValidationError
+	if errors.As(err, &validationErr) {
+		return ServerError{Message: "the request is invalid", Code: http.StatusBadRequest, Fields: validationErr.Fields}
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ServerError{Message: fmt.Sprintf("the request body may not be larger than %v bytes", maxBytesErr.Limit), Code: http.StatusRequestEntityTooLarge}
+	}
+
+	var mediaTypeErr *bind.UnsupportedMediaTypeError
+	if errors.As(err, &mediaTypeErr) {
+		return ServerError{Message: mediaTypeErr.Error(), Code: http.StatusUnsupportedMediaType}
+	}
+
+	code, detail := osErrorCode(err)
+	wrapped := Wrap(err, code)
+	wrapped.Detail = detail
+
+	return wrapped
+}
+
+func osErrorCode(err error) (int, string) {
+	switch {
+	case errors.Is(err, sandbox.ErrOutsideSandbox):
+		return http.StatusForbidden, ""
+	case errors.Is(err, syscall.EXDEV):
+		return http.StatusConflict, "the source and destination are on different file systems, copy the files instead"
+	case errors.Is(err, syscall.ENOSPC):
+		return http.StatusInsufficientStorage, "there is not enough space left on the disk"
+	case errors.Is(err, syscall.ENOTEMPTY):
+		return http.StatusConflict, "the folder is not empty"
+	case errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound, ""
+	case errors.Is(err, fs.ErrPermission):
+		return http.StatusForbidden, ""
+	case errors.Is(err, fs.ErrExist):
+		return http.StatusConflict, ""
+	}
+
+	return http.StatusInternalServerError, ""
+}
@@ -0,0 +1,110 @@
+package srverr
+
+import (
+	"errors"
+	"fmt"
+	"golang-web-core/util/bind"
+	"golang-web-core/util/sandbox"
+	"io/fs"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestFromError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantPath   string
+		wantDetail bool
+	}{
+		{
+			name:     "Outside the sandbox",
+			err:      &fs.PathError{Op: "open", Path: "/etc/passwd", Err: sandbox.ErrOutsideSandbox},
+			wantCode: http.StatusForbidden,
+			wantPath: "/etc/passwd",
+		},
+		{
+			name:       "Cross device rename",
+			err:        &os.LinkError{Op: "rename", Old: "/home/a", New: "/mnt/b", Err: syscall.EXDEV},
+			wantCode:   http.StatusConflict,
+			wantPath:   "/home/a",
+			wantDetail: true,
+		},
+		{
+			name:       "Disk full",
+			err:        &fs.PathError{Op: "write", Path: "/home/a", Err: syscall.ENOSPC},
+			wantCode:   http.StatusInsufficientStorage,
+			wantPath:   "/home/a",
+			wantDetail: true,
+		},
+		{
+			name:       "Folder not empty",
+			err:        &fs.PathError{Op: "remove", Path: "/home/a", Err: syscall.ENOTEMPTY},
+			wantCode:   http.StatusConflict,
+			wantPath:   "/home/a",
+			wantDetail: true,
+		},
+		{
+			name:     "Missing file",
+			err:      &fs.PathError{Op: "open", Path: "/home/a", Err: syscall.ENOENT},
+			wantCode: http.StatusNotFound,
+			wantPath: "/home/a",
+		},
+		{
+			name:     "Permission denied",
+			err:      &fs.PathError{Op: "open", Path: "/home/a", Err: syscall.EACCES},
+			wantCode: http.StatusForbidden,
+			wantPath: "/home/a",
+		},
+		{
+			name:     "Already exists",
+			err:      fmt.Errorf("could not create: %w", &fs.PathError{Op: "mkdir", Path: "/home/a", Err: syscall.EEXIST}),
+			wantCode: http.StatusConflict,
+			wantPath: "/home/a",
+		},
+		{
+			name:     "Validation error",
+			err:      &bind.ValidationError{Fields: []bind.FieldError{{Field: "path", Message: "is required"}}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Unsupported content type",
+			err:      &bind.UnsupportedMediaTypeError{MediaType: "text/plain"},
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "Body too large",
+			err:      &http.MaxBytesError{Limit: 10},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "Server error",
+			err:      New("teapot", http.StatusTeapot),
+			wantCode: http.StatusTeapot,
+		},
+		{
+			name:     "Anything else",
+			err:      errors.New("something broke"),
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srvErr := FromError(tc.err)
+
+			if srvErr.Code != tc.wantCode {
+				t.Errorf("Expected code %v, but got %v", tc.wantCode, srvErr.Code)
+			}
+			if srvErr.Path != tc.wantPath {
+				t.Errorf("Expected path %q, but got %q", tc.wantPath, srvErr.Path)
+			}
+			if (srvErr.Detail != "") != tc.wantDetail {
+				t.Errorf("Expected a detail: %v, but got %q", tc.wantDetail, srvErr.Detail)
+			}
+		})
+	}
+}
@@ -1,5 +1,12 @@
 package srverr
 
+import (
+	"errors"
+	"golang-web-core/util/bind"
+	"io/fs"
+	"os"
+)
+
 type SrvErr interface {
 	IsSrvErr() bool
 }
@@ -7,6 +14,14 @@ type SrvErr interface {
 type ServerError struct {
 	Message string
 	Code    int
+	// Path is the file or folder the error is about, if there is one
+	Path string
+	// Detail is an optional explanation of what the client can do about the error
+	Detail string
+	// Fields lists the request fields that failed validation
+	Fields []bind.FieldError
+	// public marks messages that were written for clients, see Public
+	public bool
 }
 
 func New(message string, code ...int) ServerError {
@@ -30,9 +45,18 @@ func Wrap(err error, code ...int) ServerError {
 	return ServerError{
 		Message: err.Error(),
 		Code:    actualCode,
+		Path:    errorPath(err),
 	}
 }
 
+// Public wraps an error whose message was written for clients, so it is sent even with a 5xx code. the
+// messages of other 5xx errors are replaced with the status text since they can contain paths and other internals
+func Public(err error, code int) ServerError {
+	srvErr := Wrap(err, code)
+	srvErr.public = true
+	return srvErr
+}
+
 func (e ServerError) Error() string {
 	return e.Message
 }
@@ -40,3 +64,18 @@ func (e ServerError) Error() string {
 func (e ServerError) IsSrvErr() bool {
 	return true
 }
+
+// errorPath digs the offending path out of wrapped os errors
+func errorPath(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Path
+	}
+
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) {
+		return linkErr.Old
+	}
+
+	return ""
+}
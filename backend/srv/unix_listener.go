@@ -0,0 +1,122 @@
+package srv
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// peerCredListener only accepts connections from processes running as the same user as the server
+type peerCredListener struct {
+	*net.UnixListener
+	uid int
+}
+
+func (l peerCredListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.AcceptUnix()
+		if err != nil {
+			return nil, err
+		}
+
+		uid, err := peerUID(conn)
+		if err != nil {
+			slog.Warn("rejected unix socket connection, unable to read peer credentials", "error", err)
+			conn.Close()
+			continue
+		}
+
+		if uid != l.uid {
+			slog.Warn("rejected unix socket connection from another user", "uid", uid)
+			conn.Close()
+			continue
+		}
+
+		return conn, nil
+	}
+}
+
+func peerUID(conn *net.UnixConn) (int, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return -1, err
+	}
+
+	var cred *unix.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		return -1, err
+	}
+	if credErr != nil {
+		return -1, credErr
+	}
+
+	return int(cred.Uid), nil
+}
+
+func listenUnix(path string) (net.Listener, error) {
+	err := removeStaleSocket(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	// the umask is process wide, so the permissions are fixed up right after bind instead. connections from other
+	// users that slip in before that are still turned away by peerCredListener
+	err = os.Chmod(path, 0o600)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return peerCredListener{UnixListener: l, uid: os.Getuid()}, nil
+}
+
+// restrictUnixListeners applies the same peer credential check as listenUnix to unix sockets that were created
+// by someone else, like systemd
+func restrictUnixListeners(listeners []net.Listener) []net.Listener {
+	for i, l := range listeners {
+		if unixListener, ok := l.(*net.UnixListener); ok {
+			listeners[i] = peerCredListener{UnixListener: unixListener, uid: os.Getuid()}
+		}
+	}
+	return listeners
+}
+
+// removeStaleSocket removes a socket left behind by a server that did not shut down cleanly
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%v already exists and is not a socket", path)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("another server is already listening on %v", path)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return err
+	}
+
+	return os.Remove(path)
+}
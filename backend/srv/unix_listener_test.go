@@ -0,0 +1,103 @@
+package srv
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lfe.sock")
+
+	l, err := listenUnix(path)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, but got %v", info.Mode().Perm())
+	}
+
+	_, err = listenUnix(path)
+	if err == nil {
+		t.Errorf("Expected an error when another server is listening, but got nil")
+	}
+}
+
+func TestPeerCredListener(t *testing.T) {
+	testCases := []struct {
+		name           string
+		uid            int
+		expectAccepted bool
+	}{
+		{name: "Same user", uid: os.Getuid(), expectAccepted: true},
+		{name: "Another user", uid: os.Getuid() + 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lfe.sock")
+			unixListener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := peerCredListener{UnixListener: unixListener, uid: tc.uid}
+
+			accepted := make(chan net.Conn, 1)
+			acceptErr := make(chan error, 1)
+			go func() {
+				conn, err := l.Accept()
+				if err != nil {
+					acceptErr <- err
+					return
+				}
+				accepted <- conn
+			}()
+
+			client, err := net.Dial("unix", path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			if tc.expectAccepted {
+				select {
+				case conn := <-accepted:
+					conn.Close()
+				case err := <-acceptErr:
+					t.Fatalf("Expected the connection to be accepted, but got: %v", err)
+				case <-time.After(5 * time.Second):
+					t.Fatalf("Expected the connection to be accepted, but timed out")
+				}
+				l.Close()
+				return
+			}
+
+			// the server closes a rejected connection and keeps waiting for the next one
+			client.SetReadDeadline(time.Now().Add(5 * time.Second))
+			_, err = client.Read(make([]byte, 1))
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("Expected the connection to be closed, but got: %v", err)
+			}
+
+			l.Close()
+			select {
+			case conn := <-accepted:
+				conn.Close()
+				t.Errorf("Expected the connection to be rejected, but it was accepted")
+			case <-acceptErr:
+			case <-time.After(5 * time.Second):
+				t.Errorf("Expected Accept to return after the listener closed, but timed out")
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+package srv
+
+// Version is set at build time with -ldflags "-X golang-web-core/srv.Version=1.2.3"
+var Version = "dev"
@@ -0,0 +1,367 @@
+package bind
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxMemory is how much of a multipart form is kept in memory, the rest is stored in temporary files
+const DefaultMaxMemory = 32 << 20
+
+var fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+
+// Request decodes req into a new T and validates it, see Into
+func Request[T any](req *http.Request) (T, error) {
+	var dst T
+	err := Into(req, &dst)
+	return dst, err
+}
+
+// Into fills the struct dst points to from the request and validates it. the json body is decoded first, then
+// fields tagged with `path:"name"`, `query:"name"` or `form:"name"` are set from the path values, query string or
+// form. repeated query and form keys can be bound to slices. fields are checked against their `validate` tags
+// afterwards, see Validate
+func Into(req *http.Request, dst any) error {
+	value := reflect.ValueOf(dst)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: expected a pointer to a struct, got %T", dst)
+	}
+
+	validationErr := &ValidationError{}
+	// sent records which fields the request contained, so required can tell a missing field from a 0 or false
+	sent := sentFields{}
+
+	err := decodeBody(req, dst, validationErr, sent)
+	if err != nil {
+		return err
+	}
+
+	err = bindValues(req, value.Elem(), validationErr, sent)
+	if err != nil {
+		return err
+	}
+
+	// there is no point in validating fields that could not be decoded
+	if len(validationErr.Fields) > 0 {
+		return validationErr
+	}
+
+	validateStruct(value.Elem(), validationErr, sent)
+	return validationErr.orNil()
+}
+
+// sentFields are the fields a request contained by source and name. json keys are stored in lowercase since
+// encoding/json matches them case insensitively
+type sentFields map[string]bool
+
+func (s sentFields) add(source, name string) {
+	if source == SourceBody {
+		name = strings.ToLower(name)
+	}
+	s[source+":"+name] = true
+}
+
+func (s sentFields) contains(source, name string) bool {
+	if source == SourceBody {
+		name = strings.ToLower(name)
+	}
+	return s[source+":"+name]
+}
+
+// peekSize is how much of the body is looked at before decoding, to skip empty bodies and to recognize json sent
+// as a form
+const peekSize = 512
+
+// bufferedBody replaces the request body after it was peeked at, so the peeked bytes are not lost to later reads
+type bufferedBody struct {
+	*bufio.Reader
+	io.Closer
+}
+
+// decodeBody decodes a json body into dst while streaming it. forms are left to bindValues and any other content
+// type is rejected with an *UnsupportedMediaTypeError. clients like curl send json with a form content type by
+// default, so form bodies that look like a json object are decoded as json as well
+func decodeBody(req *http.Request, dst any, validationErr *ValidationError, sent sentFields) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	body := bufio.NewReaderSize(req.Body, peekSize)
+	req.Body = bufferedBody{Reader: body, Closer: req.Body}
+
+	start, err := body.Peek(peekSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return readError(err)
+	}
+	if errors.Is(err, io.EOF) && len(bytes.TrimSpace(start)) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+	case mediaType == "application/x-www-form-urlencoded" && bytes.HasPrefix(bytes.TrimSpace(start), []byte("{")):
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		return nil
+	default:
+		return &UnsupportedMediaTypeError{MediaType: mediaType}
+	}
+
+	err = json.NewDecoder(body).Decode(&jsonBody{dst: dst, sent: sent})
+	if err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			validationErr.add(typeErr.Field, SourceBody, "must be of type %v", jsonTypeName(typeErr.Type))
+			return nil
+		}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("the request body is not valid json: %w", err)
+		}
+		return readError(err)
+	}
+
+	return nil
+}
+
+func readError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return err
+	}
+	return fmt.Errorf("could not read the request body: %w", err)
+}
+
+// jsonBody decodes a json object into dst and records which of its keys were sent. the decoder hands it the
+// object as one slice, so the body does not have to be read into memory first
+type jsonBody struct {
+	dst  any
+	sent sentFields
+}
+
+func (b jsonBody) UnmarshalJSON(data []byte) error {
+	err := json.Unmarshal(data, b.dst)
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]json.RawMessage{}
+	if json.Unmarshal(data, &keys) == nil {
+		for key, raw := range keys {
+			if string(raw) != "null" {
+				b.sent.add(SourceBody, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func bindValues(req *http.Request, value reflect.Value, validationErr *ValidationError, sent sentFields) error {
+	var query map[string][]string
+	var form *multipart.Form
+
+	for _, field := range fields(value) {
+		switch field.source {
+		case SourcePath:
+			raw := req.PathValue(field.name)
+			if raw == "" {
+				continue
+			}
+			sent.add(field.source, field.name)
+			setField(field, []string{raw}, validationErr)
+		case SourceQuery:
+			if query == nil {
+				query = req.URL.Query()
+			}
+			if raw, ok := query[field.name]; ok {
+				sent.add(field.source, field.name)
+				setField(field, raw, validationErr)
+			}
+		case SourceForm:
+			if form == nil {
+				var err error
+				form, err = parseForm(req)
+				if err != nil {
+					return err
+				}
+			}
+
+			if field.value.Type() == fileHeaderType || field.value.Type() == reflect.SliceOf(fileHeaderType) {
+				if len(form.File[field.name]) > 0 {
+					sent.add(field.source, field.name)
+				}
+				setFiles(field, form.File[field.name])
+			} else if raw, ok := form.Value[field.name]; ok {
+				sent.add(field.source, field.name)
+				setField(field, raw, validationErr)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseForm reads urlencoded and multipart forms into a multipart.Form so both can be bound the same way
+func parseForm(req *http.Request) (*multipart.Form, error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "multipart/form-data":
+		if req.MultipartForm == nil {
+			err := req.ParseMultipartForm(DefaultMaxMemory)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse the form: %w", err)
+			}
+		}
+		return req.MultipartForm, nil
+	case "application/x-www-form-urlencoded":
+		err := req.ParseForm()
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the form: %w", err)
+		}
+		return &multipart.Form{Value: req.PostForm}, nil
+	}
+
+	return &multipart.Form{}, nil
+}
+
+type boundField struct {
+	name   string
+	source string
+	value  reflect.Value
+}
+
+// fields lists the struct fields that are bound from the path, query or form, including those of embedded structs
+func fields(value reflect.Value) []boundField {
+	bound := []boundField{}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bound = append(bound, fields(value.Field(i))...)
+			continue
+		}
+
+		for _, source := range []string{SourcePath, SourceQuery, SourceForm} {
+			name, ok := field.Tag.Lookup(source)
+			if ok && name != "" && name != "-" {
+				bound = append(bound, boundField{name: name, source: source, value: value.Field(i)})
+				break
+			}
+		}
+	}
+
+	return bound
+}
+
+func setField(field boundField, raw []string, validationErr *ValidationError) {
+	target := field.value
+
+	if target.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(target.Type(), len(raw), len(raw))
+		for i, r := range raw {
+			err := parseValue(slice.Index(i), r)
+			if err != nil {
+				validationErr.add(field.name, field.source, "%v", err.Error())
+				return
+			}
+		}
+		target.Set(slice)
+		return
+	}
+
+	if target.Kind() == reflect.Pointer {
+		elem := reflect.New(target.Type().Elem())
+		err := parseValue(elem.Elem(), raw[0])
+		if err != nil {
+			validationErr.add(field.name, field.source, "%v", err.Error())
+			return
+		}
+		target.Set(elem)
+		return
+	}
+
+	err := parseValue(target, raw[0])
+	if err != nil {
+		validationErr.add(field.name, field.source, "%v", err.Error())
+	}
+}
+
+func setFiles(field boundField, files []*multipart.FileHeader) {
+	if len(files) == 0 {
+		return
+	}
+
+	if field.value.Kind() == reflect.Slice {
+		field.value.Set(reflect.ValueOf(files))
+		return
+	}
+	field.value.Set(reflect.ValueOf(files[0]))
+}
+
+func parseValue(target reflect.Value, raw string) error {
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		target.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive integer")
+		}
+		target.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		target.SetFloat(f)
+	default:
+		return fmt.Errorf("cannot be bound to a %v", target.Type())
+	}
+
+	return nil
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return t.Kind().String()
+}
@@ -0,0 +1,297 @@
+package bind
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type listParams struct {
+	Folder string   `path:"folder"`
+	Sort   string   `query:"sort" validate:"oneof=name size"`
+	Limit  int      `query:"limit" validate:"min=1,max=100"`
+	Tags   []string `query:"tag"`
+	Hidden *bool    `query:"hidden"`
+}
+
+type settingParams struct {
+	Enabled bool   `json:"enabled" validate:"required"`
+	Count   int    `query:"count" validate:"required,max=10"`
+	Label   string `json:"label" validate:"required"`
+}
+
+type createParams struct {
+	Name    string `json:"name" validate:"required,max=8"`
+	Mode    int    `json:"mode"`
+	DryRun  bool   `query:"dryRun"`
+	Comment string `form:"comment"`
+}
+
+type noteParams struct {
+	Name string `json:"name"`
+	Mode int    `json:"mode"`
+}
+
+func TestInto(t *testing.T) {
+	hidden := true
+
+	testCases := []struct {
+		name        string
+		method      string
+		url         string
+		contentType string
+		body        string
+		pathValues  map[string]string
+		dst         any
+		want        any
+		wantFields  []FieldError
+		expectError bool
+	}{
+		{
+			name:       "Path and Query Values",
+			method:     http.MethodGet,
+			url:        "/?sort=size&limit=10&tag=a&tag=b&hidden=true",
+			pathValues: map[string]string{"folder": "docs"},
+			dst:        &listParams{},
+			want:       &listParams{Folder: "docs", Sort: "size", Limit: 10, Tags: []string{"a", "b"}, Hidden: &hidden},
+		},
+		{
+			name:   "Unset Optional Fields Are Not Validated",
+			method: http.MethodGet,
+			url:    "/",
+			dst:    &listParams{},
+			want:   &listParams{},
+		},
+		{
+			name:   "Invalid Query Values",
+			method: http.MethodGet,
+			url:    "/?limit=abc&hidden=maybe",
+			dst:    &listParams{},
+			wantFields: []FieldError{
+				{Field: "limit", Source: SourceQuery, Message: "must be an integer"},
+				{Field: "hidden", Source: SourceQuery, Message: "must be true or false"},
+			},
+		},
+		{
+			name:   "Failed Validation",
+			method: http.MethodGet,
+			url:    "/?sort=date&limit=500",
+			dst:    &listParams{},
+			wantFields: []FieldError{
+				{Field: "sort", Source: SourceQuery, Message: "must be one of name, size"},
+				{Field: "limit", Source: SourceQuery, Message: "must be at most 100"},
+			},
+		},
+		{
+			name:        "JSON Body and Query",
+			method:      http.MethodPost,
+			url:         "/?dryRun=true",
+			contentType: "application/json",
+			body:        `{"name":"notes","mode":420}`,
+			dst:         &createParams{},
+			want:        &createParams{Name: "notes", Mode: 420, DryRun: true},
+		},
+		{
+			name:        "Missing Required Field",
+			method:      http.MethodPost,
+			url:         "/",
+			contentType: "application/json",
+			body:        `{"mode":420}`,
+			dst:         &createParams{},
+			wantFields:  []FieldError{{Field: "name", Source: SourceBody, Message: "is required"}},
+		},
+		{
+			name:        "Required Fields Sent as Zero Values",
+			method:      http.MethodPost,
+			url:         "/?count=0",
+			contentType: "application/json",
+			body:        `{"enabled":false,"label":"off"}`,
+			dst:         &settingParams{},
+			want:        &settingParams{Enabled: false, Count: 0, Label: "off"},
+		},
+		{
+			name:        "Required Fields Missing, Null or Empty",
+			method:      http.MethodPost,
+			url:         "/",
+			contentType: "application/json",
+			body:        `{"enabled":null,"label":""}`,
+			dst:         &settingParams{},
+			wantFields: []FieldError{
+				{Field: "enabled", Source: SourceBody, Message: "is required"},
+				{Field: "count", Source: SourceQuery, Message: "is required"},
+				{Field: "label", Source: SourceBody, Message: "is required"},
+			},
+		},
+		{
+			name:        "Wrong JSON Type",
+			method:      http.MethodPost,
+			url:         "/",
+			contentType: "application/json",
+			body:        `{"name":"notes","mode":"rw"}`,
+			dst:         &createParams{},
+			wantFields:  []FieldError{{Field: "mode", Source: SourceBody, Message: "must be of type integer"}},
+		},
+		{
+			name:        "Invalid JSON",
+			method:      http.MethodPost,
+			url:         "/",
+			contentType: "application/json",
+			body:        `{"name":`,
+			dst:         &createParams{},
+			expectError: true,
+		},
+		{
+			name:        "Urlencoded Form",
+			method:      http.MethodPost,
+			url:         "/",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"comment": {"hello"}}.Encode(),
+			dst: &struct {
+				Comment string `form:"comment" validate:"required"`
+			}{},
+			want: &struct {
+				Comment string `form:"comment" validate:"required"`
+			}{Comment: "hello"},
+		},
+		{
+			name:        "Not a Struct Pointer",
+			method:      http.MethodGet,
+			url:         "/",
+			dst:         listParams{},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			for key, value := range tc.pathValues {
+				req.SetPathValue(key, value)
+			}
+
+			err := Into(req, tc.dst)
+
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("Into() expected an error, got nil")
+				}
+				return
+			}
+
+			if tc.wantFields != nil {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Into() error = %v, want a *ValidationError", err)
+				}
+				if !reflect.DeepEqual(validationErr.Fields, tc.wantFields) {
+					t.Errorf("Into() fields = %+v, want %+v", validationErr.Fields, tc.wantFields)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Into() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.dst, tc.want) {
+				t.Errorf("Into() = %+v, want %+v", tc.dst, tc.want)
+			}
+		})
+	}
+}
+
+func TestContentTypes(t *testing.T) {
+	testCases := []struct {
+		name              string
+		contentType       string
+		body              io.Reader
+		want              noteParams
+		expectUnsupported bool
+	}{
+		{name: "JSON", contentType: "application/json", body: strings.NewReader(`{"name":"notes"}`), want: noteParams{Name: "notes"}},
+		{name: "JSON With Charset", contentType: "application/json; charset=utf-8", body: strings.NewReader(`{"name":"notes"}`), want: noteParams{Name: "notes"}},
+		{name: "JSON Suffix", contentType: "application/merge-patch+json", body: strings.NewReader(`{"name":"notes"}`), want: noteParams{Name: "notes"}},
+		{name: "JSON Sent as a Form", contentType: "application/x-www-form-urlencoded", body: strings.NewReader(` {"name":"notes"}`), want: noteParams{Name: "notes"}},
+		{name: "Empty Body Without Content Type", body: strings.NewReader("")},
+		{name: "Body Without Content Type", body: strings.NewReader(`{"name":"notes"}`), expectUnsupported: true},
+		{name: "Unknown Content Type", contentType: "text/plain", body: strings.NewReader(`{"name":"notes"}`), expectUnsupported: true},
+		{
+			name:        "Body Larger Than the Peek Buffer",
+			contentType: "application/json",
+			body:        strings.NewReader(`{"mode":1,"name":"notes"` + strings.Repeat(" ", 4*peekSize) + `}`),
+			want:        noteParams{Name: "notes", Mode: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", tc.body)
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			params, err := Request[noteParams](req)
+
+			if tc.expectUnsupported {
+				var unsupported *UnsupportedMediaTypeError
+				if !errors.As(err, &unsupported) {
+					t.Fatalf("Request() error = %v, want an *UnsupportedMediaTypeError", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Request() unexpected error: %v", err)
+			}
+			if params != tc.want {
+				t.Errorf("Request() = %+v, want %+v", params, tc.want)
+			}
+		})
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"`+strings.Repeat("a", 2*peekSize)+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Body = http.MaxBytesReader(rw, req.Body, peekSize+10)
+
+	_, err := Request[createParams](req)
+
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		t.Errorf("Request() error = %v, want a *http.MaxBytesError", err)
+	}
+}
+
+func TestMultipartFiles(t *testing.T) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	writer.WriteField("comment", "upload")
+	part, _ := writer.CreateFormFile("file", "a.txt")
+	part.Write([]byte("data"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	params, err := Request[struct {
+		Comment string                `form:"comment"`
+		File    *multipart.FileHeader `form:"file" validate:"required"`
+	}](req)
+	if err != nil {
+		t.Fatalf("Request() unexpected error: %v", err)
+	}
+	if params.Comment != "upload" || params.File == nil || params.File.Filename != "a.txt" {
+		t.Errorf("Request() = %+v, want the comment and a.txt", params)
+	}
+}
@@ -0,0 +1,61 @@
+package bind
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SourcePath  = "path"
+	SourceQuery = "query"
+	SourceForm  = "form"
+	SourceBody  = "body"
+)
+
+// FieldError describes why a single field of the request was rejected
+type FieldError struct {
+	// Field is the name the client used, e.g. the query key or the json key
+	Field   string `json:"field"`
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%v %v", e.Field, e.Message)
+}
+
+// ValidationError is returned when one or more fields could not be bound or failed validation
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) add(field, source, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Source: source, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *ValidationError) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// UnsupportedMediaTypeError is returned for request bodies that are neither json nor a form
+type UnsupportedMediaTypeError struct {
+	MediaType string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	if e.MediaType == "" {
+		return "the request body has no content type, send it as application/json"
+	}
+	return fmt.Sprintf("unsupported content type %v, send the request body as application/json", e.MediaType)
+}
@@ -0,0 +1,175 @@
+package bind
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks the fields of the struct v points to against their `validate` tags. rules are separated by
+// commas:
+//
+//	required     the field must be sent. strings and lists must not be empty either, 0 and false are valid
+//	min=n, max=n numbers must be within the bounds, strings and slices must have a length within the bounds
+//	oneof=a b c  the field must be one of the space separated values
+//
+// fields that are not required are only checked when they are sent. Into knows which fields the request
+// contained, Validate on its own can't tell and treats fields holding the zero value as not sent
+func Validate(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("bind: expected a struct, got %T", v)
+	}
+
+	validationErr := &ValidationError{}
+	validateStruct(value, validationErr, nil)
+	return validationErr.orNil()
+}
+
+// validateStruct checks the fields of value. sent lists the fields the request contained, when it is nil fields
+// holding the zero value count as not sent
+func validateStruct(value reflect.Value, validationErr *ValidationError, sent sentFields) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateStruct(value.Field(i), validationErr, sent)
+			continue
+		}
+
+		rules := field.Tag.Get("validate")
+		if rules == "" || rules == "-" {
+			continue
+		}
+
+		name, source := fieldName(field)
+		isSent := !value.Field(i).IsZero()
+		if sent != nil {
+			isSent = sent.contains(source, name)
+		}
+
+		for _, message := range validateField(value.Field(i), rules, isSent) {
+			validationErr.add(name, source, "%v", message)
+		}
+	}
+}
+
+// fieldName is the name the client knows the field by
+func fieldName(field reflect.StructField) (string, string) {
+	for _, source := range []string{SourcePath, SourceQuery, SourceForm} {
+		name, ok := field.Tag.Lookup(source)
+		if ok && name != "" && name != "-" {
+			return name, source
+		}
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		name = field.Name
+	}
+	return name, SourceBody
+}
+
+func validateField(value reflect.Value, rules string, sent bool) []string {
+	ruleList := strings.Split(rules, ",")
+
+	for sent && value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			// a json null
+			sent = false
+			break
+		}
+		value = value.Elem()
+	}
+
+	if !sent || isEmpty(value) {
+		if slices.Contains(ruleList, "required") {
+			return []string{"is required"}
+		}
+		if !sent {
+			return nil
+		}
+	}
+
+	messages := []string{}
+	for _, rule := range ruleList {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		var message string
+		switch name {
+		case "required", "":
+		case "min":
+			message = checkBound(value, arg, false)
+		case "max":
+			message = checkBound(value, arg, true)
+		case "oneof":
+			options := strings.Fields(arg)
+			if !slices.Contains(options, fmt.Sprint(value.Interface())) {
+				message = fmt.Sprintf("must be one of %v", strings.Join(options, ", "))
+			}
+		default:
+			message = fmt.Sprintf("has an unknown validation rule %v", name)
+		}
+
+		if message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+// isEmpty reports whether value is an empty string, list or map, which required rejects even when it was sent
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return false
+}
+
+func checkBound(value reflect.Value, arg string, isMax bool) string {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Sprintf("has an invalid bound %v", arg)
+	}
+
+	var actual float64
+	unit := ""
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	case reflect.String:
+		actual = float64(utf8.RuneCountInString(value.String()))
+		unit = " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		actual = float64(value.Len())
+		unit = " items long"
+	default:
+		return fmt.Sprintf("cannot be compared to %v", arg)
+	}
+
+	if isMax && actual > bound {
+		return fmt.Sprintf("must be at most %v%v", arg, unit)
+	}
+	if !isMax && actual < bound {
+		return fmt.Sprintf("must be at least %v%v", arg, unit)
+	}
+	return ""
+}
@@ -2,7 +2,8 @@ package util
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 )
 
 func WrapColor(color, format string, parts ...any) string {
@@ -27,19 +28,9 @@ func PrintColor(color, format string, parts ...any) {
 	fmt.Printf("\033[38;2;%vm%v\033[0m", color, strng)
 }
 
-func LogColor(color, format string, parts ...any) {
-	strng := fmt.Sprintf(format, parts...)
-
-	_, ok := Colors[color]
-	if ok {
-		color = Colors[color]
-	}
-
-	log.Printf("\033[38;2;%vm%v\033[0m", color, strng)
-}
-
 func LogFatal(err error) {
-	log.Fatalf("\033[38;2;%vm%v\033[0m", Colors["red"], err)
+	slog.Error(err.Error())
+	os.Exit(1)
 }
 
 func LogFatalf(format string, parts ...any) {
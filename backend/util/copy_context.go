@@ -0,0 +1,39 @@
+package util
+
+import (
+	"context"
+	"io"
+)
+
+const copyChunkSize = 256 << 10
+
+// CopyContext copies from src to dst like io.Copy, but stops with ctx.Err() between chunks once ctx is cancelled
+func CopyContext(ctx context.Context, dst io.Writer, src io.Reader) (int64, error) {
+	buf := make([]byte, copyChunkSize)
+	var written int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			w, err := dst.Write(buf[:n])
+			written += int64(w)
+			if err != nil {
+				return written, err
+			}
+			if w != n {
+				return written, io.ErrShortWrite
+			}
+		}
+
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCopyContext(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		input       string
+		wantWritten int64
+		wantErr     error
+	}{
+		{
+			name:        "Copies Everything",
+			ctx:         context.Background(),
+			input:       strings.Repeat("a", copyChunkSize*2+10),
+			wantWritten: copyChunkSize*2 + 10,
+		},
+		{
+			name:        "Empty Input",
+			ctx:         context.Background(),
+			input:       "",
+			wantWritten: 0,
+		},
+		{
+			name:        "Cancelled Context",
+			ctx:         cancelled,
+			input:       "data",
+			wantWritten: 0,
+			wantErr:     context.Canceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := new(bytes.Buffer)
+
+			written, err := CopyContext(tc.ctx, dst, strings.NewReader(tc.input))
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Expected error %v, but got: %v", tc.wantErr, err)
+			}
+			if written != tc.wantWritten || int64(dst.Len()) != tc.wantWritten {
+				t.Errorf("Expected %v bytes to be written, but got %v (buffer has %v)", tc.wantWritten, written, dst.Len())
+			}
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package mongo
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 type Config struct {
+	// URI is used as it is when it is set, the other fields only name the database then
+	URI      string
 	Hostname string
 	Database string
 	Username string
@@ -10,9 +15,13 @@ type Config struct {
 }
 
 func (c Config) ConnectionString() string {
+	if c.URI != "" {
+		return c.URI
+	}
+
 	authAndHost := c.Hostname
 	if c.UsingAuth() {
-		authAndHost = fmt.Sprintf("%v:%v@%v", c.Username, c.Password, c.Hostname)
+		authAndHost = fmt.Sprintf("%v@%v", url.UserPassword(c.Username, c.Password), c.Hostname)
 	}
 	return fmt.Sprintf("mongodb://%v/%v", authAndHost, c.Database)
 }
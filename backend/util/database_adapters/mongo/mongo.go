@@ -2,7 +2,8 @@ package mongo
 
 import (
 	"context"
-	"golang-web-core/util"
+	"log/slog"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -13,12 +14,20 @@ import (
 type Mongo struct {
 	Config
 	LogTransactions bool
+	shared          *sharedClient
+}
+
+// sharedClient is the client Shared hands out, it is connected on first use and kept until Disconnect
+type sharedClient struct {
+	mu     sync.Mutex
+	client *mongo.Client
 }
 
 func NewMongoAdapter(config Config, logTransactions bool) *Mongo {
 	return &Mongo{
 		Config:          config,
 		LogTransactions: logTransactions,
+		shared:          &sharedClient{},
 	}
 }
 
@@ -36,7 +45,7 @@ func (m Mongo) Close(client *mongo.Client, ctx context.Context, cancel context.C
 
 	defer func() {
 		if err := client.Disconnect(ctx); err != nil {
-			util.LogFatal(err)
+			slog.Warn("could not disconnect from mongo", "error", err)
 		}
 	}()
 }
@@ -47,6 +56,38 @@ func (m Mongo) Connect() (*mongo.Client, context.Context, context.CancelFunc, er
 	return client, ctx, cancel, err
 }
 
+// Shared is like Connect, but every call returns the same client so its connection pool is reused. only cancel
+// has to be called when the operation is done, the client stays connected until Disconnect
+func (m Mongo) Shared(ctx context.Context) (*mongo.Client, context.Context, context.CancelFunc, error) {
+	m.shared.mu.Lock()
+	defer m.shared.mu.Unlock()
+
+	if m.shared.client == nil {
+		client, err := mongo.Connect(options.Client().ApplyURI(m.ConnectionString()))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		m.shared.client = client
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	return m.shared.client, ctx, cancel, nil
+}
+
+// Disconnect closes the client returned by Shared. the next call to Shared connects again
+func (m Mongo) Disconnect(ctx context.Context) error {
+	m.shared.mu.Lock()
+	defer m.shared.mu.Unlock()
+
+	if m.shared.client == nil {
+		return nil
+	}
+
+	err := m.shared.client.Disconnect(ctx)
+	m.shared.client = nil
+	return err
+}
+
 func (m Mongo) Ping(client *mongo.Client, ctx context.Context) error {
 	if err := client.Ping(ctx, readpref.Primary()); err != nil {
 		return err
@@ -58,7 +99,7 @@ func (m Mongo) InsertOne(client *mongo.Client, ctx context.Context, col string,
 	collection := client.Database(m.Database).Collection(col)
 	res, err := collection.InsertOne(ctx, doc)
 	if m.LogTransactions {
-		util.LogColor("lightgreen", "Inserted one document into collection %v", col)
+		slog.Debug("inserted one document", "collection", col)
 	}
 	return res, err
 }
@@ -67,7 +108,7 @@ func (m Mongo) InsertMany(client *mongo.Client, ctx context.Context, col string,
 	collection := client.Database(m.Database).Collection(col)
 	res, err := collection.InsertMany(ctx, docs)
 	if m.LogTransactions {
-		util.LogColor("lightgreen", "Inserted %v documents into collection %v", len(res.InsertedIDs), col)
+		slog.Debug("inserted documents", "collection", col, "count", len(res.InsertedIDs))
 	}
 	return err
 }
@@ -76,7 +117,7 @@ func (m Mongo) Query(client *mongo.Client, ctx context.Context, col string, quer
 	collection := client.Database(m.Database).Collection(col)
 	result, err := collection.Find(ctx, query, options.Find().SetProjection(field))
 	if m.LogTransactions && result != nil {
-		util.LogColor("lightblue", "Queried %v documents from collection %v", result.RemainingBatchLength(), col)
+		slog.Debug("queried documents", "collection", col, "count", result.RemainingBatchLength())
 	}
 	return result, err
 }
@@ -85,7 +126,7 @@ func (m Mongo) UpdateOne(client *mongo.Client, ctx context.Context, col string,
 	collection := client.Database(m.Database).Collection(col)
 	_, err := collection.UpdateOne(ctx, filter, update)
 	if m.LogTransactions {
-		util.LogColor("lightyellow", "Updated one document in collection %v", col)
+		slog.Debug("updated one document", "collection", col)
 	}
 	return err
 }
@@ -94,7 +135,7 @@ func (m Mongo) UpdateMany(client *mongo.Client, ctx context.Context, col string,
 	collection := client.Database(m.Database).Collection(col)
 	res, err := collection.UpdateMany(ctx, filter, update)
 	if m.LogTransactions {
-		util.LogColor("lightyellow", "Updated %v documents in collection %v", res.ModifiedCount, col)
+		slog.Debug("updated documents", "collection", col, "count", res.ModifiedCount)
 	}
 	return err
 }
@@ -103,7 +144,7 @@ func (m Mongo) DeleteOne(client *mongo.Client, ctx context.Context, col string,
 	collection := client.Database(m.Database).Collection(col)
 	_, err := collection.DeleteOne(ctx, query)
 	if m.LogTransactions {
-		util.LogColor("lightred", "Deleted one document from collection %v", col)
+		slog.Debug("deleted one document", "collection", col)
 	}
 	return err
 }
@@ -112,7 +153,7 @@ func (m Mongo) DeleteMany(client *mongo.Client, ctx context.Context, col string,
 	collection := client.Database(m.Database).Collection(col)
 	res, err := collection.DeleteMany(ctx, query)
 	if m.LogTransactions {
-		util.LogColor("lightred", "Deleted %v documents from collection %v", res.DeletedCount, col)
+		slog.Debug("deleted documents", "collection", col, "count", res.DeletedCount)
 	}
 	return err
 }
@@ -2,6 +2,7 @@ package util
 
 import (
 	"encoding/json"
+	"golang-web-core/util/bind"
 	"io"
 	"net/http"
 )
@@ -44,7 +45,7 @@ func DecodeRequestBodyToMap(req *http.Request) (map[string]any, error) {
 }
 
 func DecodeFormDataToMap(req *http.Request, maxSize ...int64) (map[string]any, error) {
-	size := int64(100)
+	size := int64(bind.DefaultMaxMemory >> 20)
 	if len(maxSize) > 0 {
 		size = maxSize[0]
 	}
@@ -56,7 +57,7 @@ func DecodeFormDataToMap(req *http.Request, maxSize ...int64) (map[string]any, e
 
 	decoded := map[string]any{}
 	for key, value := range req.MultipartForm.Value {
-		decoded[key] = value[0]
+		decoded[key] = paramValue(value)
 	}
 
 	for key, value := range req.MultipartForm.File {
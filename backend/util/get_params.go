@@ -2,11 +2,16 @@ package util
 
 import (
 	"encoding/json"
+	"golang-web-core/util/bind"
+	"mime"
 	"net/http"
 )
 
+// GetParams reads the query string of GET requests and the json, urlencoded or multipart body of everything else
+// into a map. keys that are repeated become a []string. maxSize is how many megabytes of a multipart form are kept
+// in memory, the rest is stored in temporary files. the body is consumed
 func GetParams(req *http.Request, maxSize ...int64) (map[string]any, error) {
-	size := int64(100)
+	size := int64(bind.DefaultMaxMemory >> 20)
 	if len(maxSize) > 0 {
 		size = maxSize[0]
 	}
@@ -15,30 +20,52 @@ func GetParams(req *http.Request, maxSize ...int64) (map[string]any, error) {
 		queryValues := req.URL.Query()
 		params := make(map[string]any)
 		for key, value := range queryValues {
-			params[key] = value[0]
+			params[key] = paramValue(value)
 		}
 		return params, nil
 	}
 
-	params, err := DecodeRequestBodyToMap(req)
-	if err != nil {
-		params, err = DecodeFormDataToMap(req, size)
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		return DecodeFormDataToMap(req, size)
+	case "application/x-www-form-urlencoded":
+		err := req.ParseForm()
 		if err != nil {
 			return nil, err
 		}
+		params := make(map[string]any)
+		for key, value := range req.PostForm {
+			params[key] = paramValue(value)
+		}
+		return params, nil
 	}
 
-	return params, nil
+	return DecodeRequestBodyToMap(req)
+}
+
+func paramValue(values []string) any {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
 }
 
 type ParamsKeyType string
 
 const ParamsKey ParamsKeyType = "params"
 
+// GetParamsFromContext returns the params HandleRequest stored in the context, or an empty map when there are none
 func GetParamsFromContext(req *http.Request) map[string]any {
-	return req.Context().Value(ParamsKey).(map[string]any)
+	params, ok := req.Context().Value(ParamsKey).(map[string]any)
+	if !ok {
+		return map[string]any{}
+	}
+	return params
 }
 
+// DecodeContextParams decodes the params HandleRequest stored in the context into object.
+// prefer bind.Request, which also reads path values and validates the result
 func DecodeContextParams(req *http.Request, object any) error {
 	params := GetParamsFromContext(req)
 
@@ -2,6 +2,7 @@ package util
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -25,6 +26,14 @@ func TestGetParams(t *testing.T) {
 			wantParams:  map[string]any{"name": "Alice", "age": "30", "active": "true"}, // Query params are strings
 			expectError: false,
 		},
+		{
+			name:        "GET with Repeated Query Params",
+			method:      http.MethodGet,
+			url:         "/?tag=a&tag=b&name=Alice",
+			body:        nil,
+			wantParams:  map[string]any{"tag": []string{"a", "b"}, "name": "Alice"},
+			expectError: false,
+		},
 		{
 			name:        "GET with No Params",
 			method:      http.MethodGet,
@@ -102,3 +111,36 @@ func TestGetParams(t *testing.T) {
 		})
 	}
 }
+
+func TestGetParamsFromContext(t *testing.T) {
+	testCases := []struct {
+		name       string
+		params     any
+		wantParams map[string]any
+	}{
+		{
+			name:       "Params in Context",
+			params:     map[string]any{"name": "Alice"},
+			wantParams: map[string]any{"name": "Alice"},
+		},
+		{
+			name:       "No Params in Context",
+			params:     nil,
+			wantParams: map[string]any{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.params != nil {
+				req = req.WithContext(context.WithValue(req.Context(), ParamsKey, tc.params))
+			}
+
+			params := GetParamsFromContext(req)
+			if !reflect.DeepEqual(params, tc.wantParams) {
+				t.Errorf("Params mismatch: got %#v, want %#v", params, tc.wantParams)
+			}
+		})
+	}
+}
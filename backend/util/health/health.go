@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout is how long a single check may take before it is reported as failed
+const DefaultTimeout = 5 * time.Second
+
+// Checker is implemented by repositories and controllers that depend on something that can become unavailable,
+// like a database connection or a directory. they are checked by the readiness endpoint
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to Checker
+type CheckFunc func(ctx context.Context) error
+
+// Check implements Checker.
+func (f CheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Check is a named Checker
+type Check struct {
+	Name    string
+	Checker Checker
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of all checks. Status is ok when every check passed
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Run runs the checks concurrently and returns their results in order. a check that does not return within
+// timeout fails with context.DeadlineExceeded, even if it ignores its context
+func Run(ctx context.Context, timeout time.Duration, checks []Check) Report {
+	report := Report{Status: StatusOK, Checks: make([]Result, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = run(ctx, timeout, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+func run(ctx context.Context, timeout time.Duration, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- check.Checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{Name: check.Name, Status: StatusOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// FreeSpace fails when less than minBytes are available to unprivileged users on the file system of path
+func FreeSpace(path string, minBytes uint64) Checker {
+	return CheckFunc(func(ctx context.Context) error {
+		// the syscall can't be interrupted, but it isn't started once the readiness request is gone
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var stat unix.Statfs_t
+		err := unix.Statfs(path, &stat)
+		if err != nil {
+			return fmt.Errorf("statfs %v: %w", path, err)
+		}
+
+		available := stat.Bavail * uint64(stat.Bsize)
+		if available < minBytes {
+			return fmt.Errorf("only %v MB are available on %v, at least %v MB are needed", available>>20, path, minBytes>>20)
+		}
+		return nil
+	})
+}
+
+// Writable fails when the current user can't create files in the directory at path
+func Writable(path string) Checker {
+	return CheckFunc(func(ctx context.Context) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := unix.Access(path, unix.W_OK|unix.X_OK)
+		if err != nil {
+			return fmt.Errorf("%v is not writable: %w", path, err)
+		}
+		return nil
+	})
+}
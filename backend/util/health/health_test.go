@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	ok := CheckFunc(func(ctx context.Context) error { return nil })
+	failing := CheckFunc(func(ctx context.Context) error { return errors.New("unreachable") })
+	hanging := CheckFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	testCases := []struct {
+		name       string
+		checks     []Check
+		wantStatus string
+		wantChecks []string
+	}{
+		{
+			name:       "No Checks",
+			checks:     []Check{},
+			wantStatus: StatusOK,
+			wantChecks: []string{},
+		},
+		{
+			name:       "All Pass",
+			checks:     []Check{{"a", ok}, {"b", ok}},
+			wantStatus: StatusOK,
+			wantChecks: []string{StatusOK, StatusOK},
+		},
+		{
+			name:       "One Fails",
+			checks:     []Check{{"a", ok}, {"b", failing}},
+			wantStatus: StatusFail,
+			wantChecks: []string{StatusOK, StatusFail},
+		},
+		{
+			name:       "Check Ignores Its Timeout",
+			checks:     []Check{{"a", hanging}, {"b", ok}},
+			wantStatus: StatusFail,
+			wantChecks: []string{StatusFail, StatusOK},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Now()
+			report := Run(context.Background(), 20*time.Millisecond, tc.checks)
+
+			if time.Since(start) > 500*time.Millisecond {
+				t.Errorf("Expected Run to return at the timeout, but it took %v", time.Since(start))
+			}
+			if report.Status != tc.wantStatus {
+				t.Errorf("Status mismatch: got %v, want %v", report.Status, tc.wantStatus)
+			}
+			if len(report.Checks) != len(tc.wantChecks) {
+				t.Fatalf("Expected %v results, but got %v", len(tc.wantChecks), len(report.Checks))
+			}
+			for i, result := range report.Checks {
+				if result.Name != tc.checks[i].Name || result.Status != tc.wantChecks[i] {
+					t.Errorf("Result %v mismatch: got %v %v, want %v %v", i, result.Name, result.Status, tc.checks[i].Name, tc.wantChecks[i])
+				}
+				if result.Status == StatusFail && result.Error == "" {
+					t.Errorf("Expected failed check %v to have an error", result.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+
+	err := FreeSpace(dir, 0).Check(context.Background())
+	if err != nil {
+		t.Errorf("Expected no error, but got: %v", err)
+	}
+
+	err = FreeSpace(dir, 1<<62).Check(context.Background())
+	if err == nil {
+		t.Errorf("Expected an error when requiring more space than any disk has")
+	}
+}
+
+func TestCheckersHonourContext(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name    string
+		checker Checker
+	}{
+		{name: "Free Space", checker: FreeSpace(dir, 0)},
+		{name: "Writable", checker: Writable(dir)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.checker.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err = tc.checker.Check(ctx)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, but got: %v", err)
+			}
+		})
+	}
+}
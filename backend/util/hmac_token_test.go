@@ -0,0 +1,71 @@
+package util
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSignAndVerifyToken(t *testing.T) {
+	secret := []byte("a very secret key")
+	token := SignToken(secret, "share-id.1700000000")
+
+	testCases := []struct {
+		name        string
+		secret      []byte
+		token       string
+		wantPayload string
+		expectError bool
+	}{
+		{
+			name:        "Valid token",
+			secret:      secret,
+			token:       token,
+			wantPayload: "share-id.1700000000",
+			expectError: false,
+		},
+		{
+			name:        "Wrong secret",
+			secret:      []byte("another key"),
+			token:       token,
+			expectError: true,
+		},
+		{
+			name:        "Tampered payload",
+			secret:      secret,
+			token:       SignToken(secret, "other-id.1700000000")[:10] + token[10:],
+			expectError: true,
+		},
+		{
+			name:        "Missing signature",
+			secret:      secret,
+			token:       "c2hhcmUtaWQ",
+			expectError: true,
+		},
+		{
+			name:        "Empty token",
+			secret:      secret,
+			token:       "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := VerifyToken(tc.secret, tc.token)
+
+			if tc.expectError {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Expected ErrInvalidToken, but got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+			if payload != tc.wantPayload {
+				t.Errorf("Payload mismatch: got %q, want %q", payload, tc.wantPayload)
+			}
+		})
+	}
+}
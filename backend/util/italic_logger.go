@@ -2,7 +2,6 @@ package util
 
 import (
 	"fmt"
-	"log"
 )
 
 func WrapItalicColor(color, format string, parts ...any) string {
@@ -13,16 +12,3 @@ func WrapItalicColor(color, format string, parts ...any) string {
 
 	return fmt.Sprintf("\033[3m\033[38;2;%vm%v\033[0m", color, fmt.Sprintf(format, parts...))
 }
-
-func LogItalic(format string, parts ...any) {
-	log.Printf("\033[3m%v\033[0m", fmt.Sprintf(format, parts...))
-}
-
-func LogItalicColor(color string, format string, parts ...any) {
-	_, ok := Colors[color]
-	if ok {
-		color = Colors[color]
-	}
-
-	log.Printf("\033[3m\033[38;2;%vm%v\033[0m", color, fmt.Sprintf(format, parts...))
-}
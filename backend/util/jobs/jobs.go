@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"golang-web-core/util/metrics"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	KindCopy    = "copy"
+	KindMove    = "move"
+	KindExtract = "extract"
+	KindUpload  = "upload"
+)
+
+// ErrShuttingDown is returned by Start once the tracker is draining and is the cause of the context
+// cancellation of jobs that did not finish before the shutdown deadline
+var ErrShuttingDown = errors.New("the server is shutting down")
+
+// Info describes a running job
+type Info struct {
+	ID      uint64    `json:"id"`
+	Kind    string    `json:"kind"`
+	Started time.Time `json:"started"`
+}
+
+type job struct {
+	Info
+	cancel context.CancelCauseFunc
+}
+
+// Tracker keeps track of long running jobs like copies and uploads so shutdown can wait for them
+type Tracker struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	jobs     map[uint64]*job
+	nextID   uint64
+	draining bool
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{jobs: map[uint64]*job{}}
+}
+
+// Start registers a job. the returned context is cancelled when ctx is, or with ErrShuttingDown when the job
+// did not finish before the shutdown deadline, at which point it should checkpoint and return. done must be
+// called once the job has returned
+func (t *Tracker) Start(ctx context.Context, kind string) (context.Context, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return nil, nil, ErrShuttingDown
+	}
+
+	t.nextID++
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	j := &job{Info: Info{ID: t.nextID, Kind: kind, Started: time.Now()}, cancel: cancel}
+	t.jobs[j.ID] = j
+	t.wg.Add(1)
+	metrics.ActiveJobs.Inc()
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			t.mu.Lock()
+			delete(t.jobs, j.ID)
+			t.mu.Unlock()
+
+			cancel(nil)
+			metrics.ActiveJobs.Dec()
+			t.wg.Done()
+		})
+	}
+
+	return jobCtx, done, nil
+}
+
+// Active lists the running jobs
+func (t *Tracker) Active() []Info {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	active := make([]Info, 0, len(t.jobs))
+	for _, j := range t.jobs {
+		active = append(active, j.Info)
+	}
+	return active
+}
+
+// Drain stops new jobs from starting and waits for the running ones to finish. when ctx is done first, the
+// remaining jobs are cancelled with ErrShuttingDown and Drain waits for them to return before reporting ctx.Err()
+func (t *Tracker) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	for _, j := range t.jobs {
+		slog.Warn("cancelling job that did not finish before shutdown", "job_id", j.ID, "kind", j.Kind, "running_for", time.Since(j.Started).Round(time.Second).String())
+		j.cancel(ErrShuttingDown)
+	}
+	t.mu.Unlock()
+
+	<-finished
+	return ctx.Err()
+}
@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	testCases := []struct {
+		name       string
+		jobRuntime time.Duration
+		deadline   time.Duration
+		wantErr    error
+		wantCause  error
+	}{
+		{
+			name:       "Jobs Finish Before the Deadline",
+			jobRuntime: 10 * time.Millisecond,
+			deadline:   time.Second,
+			wantErr:    nil,
+			wantCause:  nil,
+		},
+		{
+			name:       "Jobs Are Cancelled at the Deadline",
+			jobRuntime: time.Minute,
+			deadline:   20 * time.Millisecond,
+			wantErr:    context.DeadlineExceeded,
+			wantCause:  ErrShuttingDown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker := NewTracker()
+
+			ctx, done, err := tracker.Start(context.Background(), KindCopy)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			causes := make(chan error, 1)
+			go func() {
+				defer done()
+				select {
+				case <-time.After(tc.jobRuntime):
+					causes <- nil
+				case <-ctx.Done():
+					causes <- context.Cause(ctx)
+				}
+			}()
+
+			drainCtx, cancel := context.WithTimeout(context.Background(), tc.deadline)
+			defer cancel()
+
+			err = tracker.Drain(drainCtx)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Expected Drain error %v, but got: %v", tc.wantErr, err)
+			}
+			if cause := <-causes; !errors.Is(cause, tc.wantCause) {
+				t.Errorf("Expected the job to be cancelled with %v, but got: %v", tc.wantCause, cause)
+			}
+			if active := tracker.Active(); len(active) != 0 {
+				t.Errorf("Expected no active jobs after Drain, but got: %v", active)
+			}
+
+			_, _, err = tracker.Start(context.Background(), KindCopy)
+			if !errors.Is(err, ErrShuttingDown) {
+				t.Errorf("Expected ErrShuttingDown after Drain, but got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"golang-web-core/util"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var levelColors = map[slog.Level]string{
+	slog.LevelDebug: "lightgray",
+	slog.LevelInfo:  "lightblue",
+	slog.LevelWarn:  "yellow",
+	slog.LevelError: "red",
+}
+
+// consoleHandler writes human readable lines similar to the standard log package:
+// 2006/01/02 15:04:05 INFO  <request id> message key=value
+type consoleHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Leveler
+	color  bool
+	prefix string
+	attrs  string
+}
+
+func newConsoleHandler(w io.Writer, level slog.Leveler, color bool) *consoleHandler {
+	return &consoleHandler{mu: &sync.Mutex{}, w: w, level: level, color: color}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	var line strings.Builder
+
+	if !record.Time.IsZero() {
+		line.WriteString(record.Time.Format("2006/01/02 15:04:05 "))
+	}
+
+	level := fmt.Sprintf("%-5v", record.Level.String())
+	if h.color {
+		level = util.WrapColor(levelColors[record.Level], "%v", level)
+	}
+	line.WriteString(level)
+
+	requestID := ""
+	attrs := h.attrs
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == RequestIDKey && h.prefix == "" {
+			requestID = attr.Value.String()
+			return true
+		}
+		attrs += formatAttr(h.prefix, attr)
+		return true
+	})
+
+	if requestID != "" {
+		line.WriteString(" " + requestID)
+	}
+	line.WriteString(" " + record.Message)
+	line.WriteString(attrs)
+	line.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	for _, attr := range attrs {
+		clone.attrs += formatAttr(h.prefix, attr)
+	}
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix += name + "."
+	return &clone
+}
+
+func formatAttr(prefix string, attr slog.Attr) string {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return ""
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		formatted := ""
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			formatted += formatAttr(groupPrefix, groupAttr)
+		}
+		return formatted
+	}
+
+	value := attr.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+
+	return fmt.Sprintf(" %v%v=%v", prefix, attr.Key, value)
+}
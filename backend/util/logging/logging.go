@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+type Options struct {
+	Level  slog.Level
+	Format string
+	// File is where logs are written to. logs go to stderr when it is empty
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+}
+
+// Level is shared by every handler created by Setup so it can be changed while the server is running
+var Level = new(slog.LevelVar)
+
+// Setup installs the default slog logger. the returned closer closes the log file, if there is one
+func Setup(opts Options) (io.Closer, error) {
+	Level.Set(opts.Level)
+
+	var writer io.Writer = os.Stderr
+	var closer io.Closer = io.NopCloser(nil)
+	color := IsTerminal(os.Stderr)
+
+	if opts.File != "" {
+		file, err := OpenRotatingFile(opts.File, opts.MaxSizeMB, opts.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		writer = file
+		closer = file
+		color = false
+	}
+
+	handler, err := NewHandler(writer, opts.Format, color)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+
+	slog.SetDefault(slog.New(handler))
+
+	return closer, nil
+}
+
+// NewHandler creates a handler that writes in the given format and tags every line with the request id of its context
+func NewHandler(w io.Writer, format string, color bool) (slog.Handler, error) {
+	var handler slog.Handler
+
+	switch format {
+	case FormatConsole, "":
+		handler = newConsoleHandler(w, Level, color)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: Level})
+	default:
+		return nil, fmt.Errorf("unknown log format: %v", format)
+	}
+
+	return contextHandler{handler}, nil
+}
+
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+
+	return slog.LevelInfo, fmt.Errorf("unknown log level: %v", level)
+}
+
+func IsTerminal(file *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(file.Fd()), unix.TCGETS)
+	return err == nil
+}
@@ -0,0 +1,218 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConsoleHandler(t *testing.T) {
+	testCases := []struct {
+		name  string
+		log   func(logger *slog.Logger)
+		level slog.Level
+		want  string
+	}{
+		{
+			name:  "Message with attributes",
+			log:   func(l *slog.Logger) { l.Info("request started", "method", "GET", "path", "/api/apps") },
+			level: slog.LevelInfo,
+			want:  "INFO  request started method=GET path=/api/apps\n",
+		},
+		{
+			name:  "Request id from context",
+			log:   func(l *slog.Logger) { l.InfoContext(WithRequestID(context.Background(), "abc123"), "request finished") },
+			level: slog.LevelInfo,
+			want:  "INFO  abc123 request finished\n",
+		},
+		{
+			name:  "Values with spaces are quoted",
+			log:   func(l *slog.Logger) { l.Warn("bad request", "error", "missing bearer token") },
+			level: slog.LevelInfo,
+			want:  "WARN  bad request error=\"missing bearer token\"\n",
+		},
+		{
+			name:  "Groups prefix keys",
+			log:   func(l *slog.Logger) { l.WithGroup("db").Error("query failed", "collection", "apps") },
+			level: slog.LevelInfo,
+			want:  "ERROR query failed db.collection=apps\n",
+		},
+		{
+			name:  "Below level is dropped",
+			log:   func(l *slog.Logger) { l.Debug("params", "name", "Alice") },
+			level: slog.LevelInfo,
+			want:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			level := new(slog.LevelVar)
+			level.Set(tc.level)
+			logger := slog.New(contextHandler{newConsoleHandler(&buf, level, false)})
+
+			tc.log(logger)
+
+			// strip the timestamp so the output is predictable
+			got := buf.String()
+			if len(got) > 20 {
+				got = got[20:]
+			}
+			if got != tc.want {
+				t.Errorf("Output mismatch: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONHandlerIncludesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, FormatJSON, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slog.New(handler).InfoContext(WithRequestID(context.Background(), "abc123"), "request started")
+
+	var line map[string]any
+	err = json.Unmarshal(buf.Bytes(), &line)
+	if err != nil {
+		t.Fatalf("Expected valid json, but got %q: %v", buf.String(), err)
+	}
+	if line[RequestIDKey] != "abc123" {
+		t.Errorf("Expected %v to be abc123, got %v", RequestIDKey, line[RequestIDKey])
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	testCases := []struct {
+		input       string
+		want        slog.Level
+		expectError bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"", slog.LevelInfo, false},
+		{"warn", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseLevel(tc.input)
+			if (err != nil) != tc.expectError {
+				t.Errorf("ParseLevel(%q) error = %v, expectError %v", tc.input, err, tc.expectError)
+			}
+			if got != tc.want {
+				t.Errorf("ParseLevel(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	file, err := OpenRotatingFile(path, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rotate after every 10 bytes instead of every megabyte
+	file.maxSize = 10
+
+	for _, line := range []string{"first 1\n", "second 2\n", "third 3\n", "fourth 4\n"} {
+		_, err := file.Write([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	file.Close()
+
+	want := map[string]string{
+		path:        "fourth 4\n",
+		path + ".1": "third 3\n",
+		path + ".2": "second 2\n",
+	}
+	for p, content := range want {
+		bytes, err := os.ReadFile(p)
+		if err != nil {
+			t.Errorf("Expected %v to exist: %v", p, err)
+			continue
+		}
+		if string(bytes) != content {
+			t.Errorf("%v contains %q, want %q", p, string(bytes), content)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 backups to be kept")
+	}
+}
+
+func TestRotatingFileKeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	file, err := OpenRotatingFile(path, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.maxSize = 10
+
+	// a non-empty directory in place of the backup can't be removed or renamed over
+	err = os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first 1\n", "second 2\n", "third 3\n"} {
+		_, err := file.Write([]byte(line))
+		if err != nil {
+			t.Fatalf("Expected writes to keep working, but got: %v", err)
+		}
+	}
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bytes) != "first 1\nsecond 2\nthird 3\n" {
+		t.Errorf("%v contains %q, want every line", path, string(bytes))
+	}
+
+	// once the backup can be moved again, the next write rotates
+	err = os.RemoveAll(path + ".1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"fourth 4\n", "fifth 5\n"} {
+		_, err := file.Write([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bytes, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bytes) != "fifth 5\n" {
+		t.Errorf("%v contains %q, want %q", path, string(bytes), "fifth 5\n")
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "not-a-tty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if IsTerminal(file) {
+		t.Errorf("Expected a regular file not to be a terminal")
+	}
+}
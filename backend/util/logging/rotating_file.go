@@ -0,0 +1,126 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RotatingFile is a log file that is renamed to <path>.1 once it grows past its max size.
+// older files are shifted to <path>.2, <path>.3 and so on, keeping at most maxBackups of them
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func OpenRotatingFile(path string, maxSizeMB, maxBackups int) (*RotatingFile, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) << 20,
+		maxBackups: maxBackups,
+	}
+
+	err = r.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		err := r.rotate()
+		if err != nil {
+			// losing logs is worse than a file that is too large, so keep writing to the current file and
+			// try again once another maxSize bytes were written
+			fmt.Fprintf(os.Stderr, "could not rotate the log file %v: %v\n", r.path, err)
+			r.size = 0
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+func (r *RotatingFile) open() error {
+	file, size, err := openLogFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	r.file = file
+	r.size = size
+	return nil
+}
+
+func openLogFile(path string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+
+	return file, info.Size(), nil
+}
+
+// rotate moves the current file out of the way and switches to a new one. the current file is only closed once
+// the new one is open, so when anything fails logs keep going to the current file
+func (r *RotatingFile) rotate() error {
+	var err error
+	if r.maxBackups <= 0 {
+		err = os.Remove(r.path)
+	} else {
+		os.Remove(r.backupPath(r.maxBackups))
+		for i := r.maxBackups - 1; i >= 1; i-- {
+			err = os.Rename(r.backupPath(i), r.backupPath(i+1))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		err = os.Rename(r.path, r.backupPath(1))
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	file, size, err := openLogFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	old := r.file
+	r.file = file
+	r.size = size
+	return old.Close()
+}
+
+func (r *RotatingFile) backupPath(n int) string {
+	return fmt.Sprintf("%v.%v", r.path, n)
+}
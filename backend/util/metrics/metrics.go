@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets in seconds, from 5ms up to 30s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type collector interface {
+	write(w io.Writer) error
+}
+
+// Registry holds metrics and renders them in the prometheus text exposition format
+type Registry struct {
+	mu         sync.Mutex
+	names      []string
+	collectors map[string]collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: map[string]collector{}}
+}
+
+var Default = NewRegistry()
+
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collectors[name]; ok {
+		panic(fmt.Sprintf("metric %v was registered twice", name))
+	}
+	r.names = append(r.names, name)
+	r.collectors[name] = c
+}
+
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	names := slices.Clone(r.names)
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		err := r.collectors[name].write(w)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// series stores one value per combination of label values
+type series[T any] struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]T
+	keys   map[string][]string
+}
+
+func newSeries[T any](name, help, kind string, labels []string) *series[T] {
+	return &series[T]{name: name, help: help, kind: kind, labels: labels, values: map[string]T{}, keys: map[string][]string{}}
+}
+
+// get returns the value for the label values, creating it with create if it doesn't exist yet
+func (s *series[T]) get(labelValues []string, create func() T) T {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("metric %v expects %v label values, got %v", s.name, len(s.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	if !ok {
+		value = create()
+		s.values[key] = value
+		s.keys[key] = slices.Clone(labelValues)
+	}
+	return value
+}
+
+// each calls fn for every label combination in a stable order
+func (s *series[T]) each(fn func(labelValues []string, value T) error) error {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	s.mu.Unlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s.mu.Lock()
+		labelValues, value := s.keys[key], s.values[key]
+		s.mu.Unlock()
+
+		err := fn(labelValues, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *series[T]) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", s.name, escapeHelp(s.help), s.name, s.kind)
+	return err
+}
+
+func formatLabels(names, values []string, extra ...string) string {
+	pairs := []string{}
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%v="%v"`, name, escapeLabel(values[i])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%v="%v"`, extra[i], escapeLabel(extra[i+1])))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	help = strings.ReplaceAll(help, `\`, `\\`)
+	return strings.ReplaceAll(help, "\n", `\n`)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
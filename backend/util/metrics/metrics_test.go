@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.NewCounterVec("test_requests_total", "Handled requests.", "route", "status")
+	requests.With("/api/apps", "200").Inc()
+	requests.With("/api/apps", "200").Inc()
+	requests.With("/api/shares/{id}", "404").Add(3)
+	requests.With("/api/shares/{id}", "404").Add(-1) // ignored, counters only go up
+
+	jobs := registry.NewGauge("test_active_jobs", "Running jobs.")
+	jobs.Inc()
+	jobs.Inc()
+	jobs.Dec()
+
+	duration := registry.NewHistogramVec("test_duration_seconds", "Request duration.", []float64{0.1, 1}, "route")
+	duration.With("/api/apps").Observe(0.05)
+	duration.With("/api/apps").Observe(0.5)
+	duration.With("/api/apps").Observe(2)
+
+	labels := registry.NewCounterVec("test_escaping_total", "Label escaping.", "path")
+	labels.With("a \"quoted\"\\path\n").Inc()
+
+	var buf bytes.Buffer
+	err := registry.WriteText(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	want := `# HELP test_active_jobs Running jobs.
+# TYPE test_active_jobs gauge
+test_active_jobs 1
+# HELP test_duration_seconds Request duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/api/apps",le="0.1"} 1
+test_duration_seconds_bucket{route="/api/apps",le="1"} 2
+test_duration_seconds_bucket{route="/api/apps",le="+Inf"} 3
+test_duration_seconds_sum{route="/api/apps"} 2.55
+test_duration_seconds_count{route="/api/apps"} 3
+# HELP test_escaping_total Label escaping.
+# TYPE test_escaping_total counter
+test_escaping_total{path="a \"quoted\"\\path\n"} 1
+# HELP test_requests_total Handled requests.
+# TYPE test_requests_total counter
+test_requests_total{route="/api/apps",status="200"} 2
+test_requests_total{route="/api/shares/{id}",status="404"} 3
+`
+	if buf.String() != want {
+		t.Errorf("Output mismatch:\ngot:\n%v\nwant:\n%v", buf.String(), want)
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("test_total", "Concurrent counter.", "worker")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				counter.With("shared").Inc()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := counter.With("shared").v.get(); got != 10000 {
+		t.Errorf("Expected 10000, got %v", got)
+	}
+}
+
+func TestDuplicateRegistrationPanics(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("test_total", "First.")
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering the same name twice to panic")
+		}
+	}()
+	registry.NewCounter("test_total", "Second.")
+}
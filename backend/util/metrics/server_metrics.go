@@ -0,0 +1,29 @@
+package metrics
+
+// these are the metrics exposed on /metrics. subsystems update them directly
+var (
+	HTTPRequests = Default.NewCounterVec(
+		"lfe_http_requests_total",
+		"Number of handled requests by route pattern and status code.",
+		"method", "route", "status",
+	)
+	HTTPRequestDuration = Default.NewHistogramVec(
+		"lfe_http_request_duration_seconds",
+		"Time taken to handle requests by route pattern.",
+		DefaultBuckets,
+		"method", "route",
+	)
+	HandlerPanics = Default.NewCounterVec(
+		"lfe_http_handler_panics_total",
+		"Number of panics recovered in request handlers by route pattern.",
+		"method", "route",
+	)
+	ActiveJobs = Default.NewGauge(
+		"lfe_active_jobs",
+		"Number of copy, move, extract and upload jobs currently running.",
+	)
+	BytesCopied = Default.NewCounter(
+		"lfe_bytes_copied_total",
+		"Number of bytes written by copy and move operations.",
+	)
+)
@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// value is a float64 that can be updated atomically
+type value struct {
+	bits atomic.Uint64
+}
+
+func (v *value) add(delta float64) {
+	for {
+		old := v.bits.Load()
+		if v.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+func (v *value) set(f float64) {
+	v.bits.Store(math.Float64bits(f))
+}
+
+func (v *value) get() float64 {
+	return math.Float64frombits(v.bits.Load())
+}
+
+type Counter struct {
+	v *value
+}
+
+func (c Counter) Inc() {
+	c.v.add(1)
+}
+
+// Add increases the counter, negative values are ignored because counters can only go up
+func (c Counter) Add(delta float64) {
+	if delta > 0 {
+		c.v.add(delta)
+	}
+}
+
+type CounterVec struct {
+	*series[*value]
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newSeries[*value](name, help, "counter", labels)}
+	r.register(name, c)
+	return c
+}
+
+func (r *Registry) NewCounter(name, help string) Counter {
+	return r.NewCounterVec(name, help).With()
+}
+
+func (c *CounterVec) With(labelValues ...string) Counter {
+	return Counter{c.get(labelValues, func() *value { return &value{} })}
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	return writeValues(w, c.series)
+}
+
+type Gauge struct {
+	v *value
+}
+
+func (g Gauge) Set(f float64) {
+	g.v.set(f)
+}
+
+func (g Gauge) Inc() {
+	g.v.add(1)
+}
+
+func (g Gauge) Dec() {
+	g.v.add(-1)
+}
+
+func (g Gauge) Add(delta float64) {
+	g.v.add(delta)
+}
+
+type GaugeVec struct {
+	*series[*value]
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newSeries[*value](name, help, "gauge", labels)}
+	r.register(name, g)
+	return g
+}
+
+func (r *Registry) NewGauge(name, help string) Gauge {
+	return r.NewGaugeVec(name, help).With()
+}
+
+func (g *GaugeVec) With(labelValues ...string) Gauge {
+	return Gauge{g.get(labelValues, func() *value { return &value{} })}
+}
+
+func (g *GaugeVec) write(w io.Writer) error {
+	return writeValues(w, g.series)
+}
+
+func writeValues(w io.Writer, s *series[*value]) error {
+	err := s.writeHeader(w)
+	if err != nil {
+		return err
+	}
+
+	return s.each(func(labelValues []string, v *value) error {
+		_, err := fmt.Fprintf(w, "%v%v %v\n", s.name, formatLabels(s.labels, labelValues), formatFloat(v.get()))
+		return err
+	})
+}
+
+type histogramValue struct {
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type Histogram struct {
+	buckets []float64
+	h       *histogramValue
+}
+
+func (h Histogram) Observe(f float64) {
+	h.h.mu.Lock()
+	defer h.h.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		if f <= bound {
+			h.h.counts[i]++
+		}
+	}
+	h.h.count++
+	h.h.sum += f
+}
+
+type HistogramVec struct {
+	*series[*histogramValue]
+	buckets []float64
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	h := &HistogramVec{series: newSeries[*histogramValue](name, help, "histogram", labels), buckets: buckets}
+	r.register(name, h)
+	return h
+}
+
+func (h *HistogramVec) With(labelValues ...string) Histogram {
+	return Histogram{
+		buckets: h.buckets,
+		h: h.get(labelValues, func() *histogramValue {
+			return &histogramValue{counts: make([]uint64, len(h.buckets))}
+		}),
+	}
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	err := h.writeHeader(w)
+	if err != nil {
+		return err
+	}
+
+	return h.each(func(labelValues []string, v *histogramValue) error {
+		v.mu.Lock()
+		counts, count, sum := slices.Clone(v.counts), v.count, v.sum
+		v.mu.Unlock()
+
+		for i, bound := range h.buckets {
+			_, err := fmt.Fprintf(w, "%v_bucket%v %v\n", h.name, formatLabels(h.labels, labelValues, "le", formatFloat(bound)), counts[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := fmt.Fprintf(w, "%v_bucket%v %v\n%v_sum%v %v\n%v_count%v %v\n",
+			h.name, formatLabels(h.labels, labelValues, "le", "+Inf"), count,
+			h.name, formatLabels(h.labels, labelValues), formatFloat(sum),
+			h.name, formatLabels(h.labels, labelValues), count,
+		)
+		return err
+	})
+}
@@ -0,0 +1,202 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// ReadDir lists the entries of the directory at path, without the ones that are denied
+func (s *Sandbox) ReadDir(path string) ([]fs.DirEntry, error) {
+	dir, err := s.OpenFile(path, os.O_RDONLY|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	entries, err := dir.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := fdPath(int(dir.Fd()))
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := entries[:0]
+	for _, entry := range entries {
+		if !s.isDenied(filepath.Join(base, entry.Name())) {
+			allowed = append(allowed, entry)
+		}
+	}
+	return allowed, nil
+}
+
+// OpenParent opens the directory containing path as an O_PATH descriptor and returns it with the last element
+// of path. operations relative to it with the *at syscalls don't follow a symlink in place of that element, so
+// it is how files are created, renamed and removed without leaving the sandbox. the roots themselves can't be
+// modified
+func (s *Sandbox) OpenParent(path string) (*os.File, string, error) {
+	root, cleaned, err := s.split(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if cleaned == root {
+		return nil, "", &fs.PathError{Op: "resolve", Path: path, Err: fmt.Errorf("an allowed root can't be modified: %w", fs.ErrPermission)}
+	}
+
+	parent, err := s.OpenFile(filepath.Dir(cleaned), unix.O_PATH|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// the parent may have been reached through a symlink, so check where the element actually is
+	name := filepath.Base(cleaned)
+	realParent, err := fdPath(int(parent.Fd()))
+	if err != nil {
+		parent.Close()
+		return nil, "", &fs.PathError{Op: "resolve", Path: path, Err: err}
+	}
+	if s.isDenied(filepath.Join(realParent, name)) {
+		parent.Close()
+		return nil, "", &fs.PathError{Op: "resolve", Path: path, Err: ErrOutsideSandbox}
+	}
+
+	return parent, name, nil
+}
+
+func (s *Sandbox) Mkdir(path string, perm os.FileMode) error {
+	parent, name, err := s.OpenParent(path)
+	if err != nil {
+		return err
+	}
+	defer parent.Close()
+
+	err = unix.Mkdirat(int(parent.Fd()), name, uint32(perm.Perm()))
+	if err != nil {
+		return &fs.PathError{Op: "mkdir", Path: path, Err: err}
+	}
+	return nil
+}
+
+// Chmod changes the mode of path. symlinks are not followed, they can't have a mode of their own
+func (s *Sandbox) Chmod(path string, mode os.FileMode) error {
+	file, err := s.OpenFile(path, os.O_RDONLY|unix.O_NOFOLLOW, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return file.Chmod(mode)
+}
+
+// MkdirAll creates path and the parents that don't exist yet
+func (s *Sandbox) MkdirAll(path string, perm os.FileMode) error {
+	info, err := s.Stat(path)
+	if err == nil {
+		if !info.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: path, Err: unix.ENOTDIR}
+		}
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	err = s.MkdirAll(filepath.Dir(filepath.Clean(path)), perm)
+	if err != nil {
+		return err
+	}
+
+	err = s.Mkdir(path, perm)
+	if errors.Is(err, fs.ErrExist) {
+		return nil
+	}
+	return err
+}
+
+// Rename moves oldPath to newPath. unless replace is set it fails with fs.ErrExist when newPath exists. moving
+// between file systems fails with unix.EXDEV
+func (s *Sandbox) Rename(oldPath, newPath string, replace bool) error {
+	oldParent, oldName, err := s.OpenParent(oldPath)
+	if err != nil {
+		return err
+	}
+	defer oldParent.Close()
+
+	newParent, newName, err := s.OpenParent(newPath)
+	if err != nil {
+		return err
+	}
+	defer newParent.Close()
+
+	var flags uint
+	if !replace {
+		flags = unix.RENAME_NOREPLACE
+	}
+
+	err = unix.Renameat2(int(oldParent.Fd()), oldName, int(newParent.Fd()), newName, flags)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: err}
+	}
+	return nil
+}
+
+// RemoveAll removes path and everything below it. symlinks are removed, not followed, and denied paths below
+// path are left in place, in which case removing their parents fails with unix.ENOTEMPTY
+func (s *Sandbox) RemoveAll(path string) error {
+	parent, name, err := s.OpenParent(path)
+	if err != nil {
+		return err
+	}
+	defer parent.Close()
+
+	base, err := fdPath(int(parent.Fd()))
+	if err != nil {
+		return err
+	}
+
+	err = s.removeAt(int(parent.Fd()), base, name)
+	if err != nil && !errors.Is(err, unix.ENOENT) {
+		return &fs.PathError{Op: "remove", Path: path, Err: err}
+	}
+	return nil
+}
+
+func (s *Sandbox) removeAt(dirFd int, base, name string) error {
+	if s.isDenied(filepath.Join(base, name)) {
+		return unix.ENOTEMPTY
+	}
+
+	err := unix.Unlinkat(dirFd, name, 0)
+	if !errors.Is(err, unix.EISDIR) {
+		return err
+	}
+
+	fd, err := openBeneath(dirFd, name, os.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW, 0, unix.RESOLVE_BENEATH|unix.RESOLVE_NO_SYMLINKS|unix.RESOLVE_NO_MAGICLINKS)
+	if err != nil {
+		return err
+	}
+	dir := os.NewFile(uintptr(fd), filepath.Join(base, name))
+	names, err := dir.Readdirnames(-1)
+	if err == nil {
+		for _, child := range names {
+			err = s.removeAt(fd, filepath.Join(base, name), child)
+			if err != nil && !errors.Is(err, unix.ENOENT) {
+				break
+			}
+			err = nil
+		}
+	}
+	dir.Close()
+	if err != nil {
+		return err
+	}
+
+	return unix.Unlinkat(dirFd, name, unix.AT_REMOVEDIR)
+}
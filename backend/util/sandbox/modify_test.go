@@ -0,0 +1,139 @@
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadDir(t *testing.T) {
+	s, root, _ := setupSandbox(t)
+
+	entries, err := s.ReadDir(root)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+
+	// the denied folder is hidden
+	want := []string{"docs", "docs-link", "escape"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Entries mismatch: got %v, want %v", names, want)
+	}
+}
+
+func TestModify(t *testing.T) {
+	s, root, outside := setupSandbox(t)
+
+	testCases := []struct {
+		name         string
+		modify       func() error
+		expectDenied bool
+		expectErr    error
+		expectExists []string
+		expectGone   []string
+	}{
+		{
+			name:         "MkdirAll creates parents",
+			modify:       func() error { return s.MkdirAll(filepath.Join(root, "new", "nested"), 0o755) },
+			expectExists: []string{filepath.Join(root, "new", "nested")},
+		},
+		{
+			name:         "MkdirAll through an escaping symlink",
+			modify:       func() error { return s.MkdirAll(filepath.Join(root, "escape", "created"), 0o755) },
+			expectDenied: true,
+			expectGone:   []string{filepath.Join(outside, "created")},
+		},
+		{
+			name: "Rename without replacing",
+			modify: func() error {
+				return s.Rename(filepath.Join(root, "docs", "a.txt"), filepath.Join(root, "docs", "a.txt"), false)
+			},
+			expectErr: os.ErrExist,
+		},
+		{
+			name: "Rename",
+			modify: func() error {
+				return s.Rename(filepath.Join(root, "docs", "a.txt"), filepath.Join(root, "b.txt"), false)
+			},
+			expectExists: []string{filepath.Join(root, "b.txt")},
+			expectGone:   []string{filepath.Join(root, "docs", "a.txt")},
+		},
+		{
+			name:         "Rename out of the sandbox",
+			modify:       func() error { return s.Rename(filepath.Join(root, "b.txt"), filepath.Join(outside, "b.txt"), false) },
+			expectDenied: true,
+			expectExists: []string{filepath.Join(root, "b.txt")},
+		},
+		{
+			name:         "RemoveAll removes the symlink, not its target",
+			modify:       func() error { return s.RemoveAll(filepath.Join(root, "escape")) },
+			expectExists: []string{filepath.Join(outside, "secret")},
+			expectGone:   []string{filepath.Join(root, "escape")},
+		},
+		{
+			name:   "Chmod",
+			modify: func() error { return s.Chmod(filepath.Join(root, "new", "nested"), 0o700) },
+		},
+		{
+			name:         "Chmod out of the sandbox",
+			modify:       func() error { return s.Chmod(root+"/../outside/secret", 0o777) },
+			expectDenied: true,
+		},
+		{
+			name:       "RemoveAll",
+			modify:     func() error { return s.RemoveAll(filepath.Join(root, "new")) },
+			expectGone: []string{filepath.Join(root, "new")},
+		},
+		{
+			name:         "RemoveAll of a denied path",
+			modify:       func() error { return s.RemoveAll(filepath.Join(root, "private")) },
+			expectDenied: true,
+			expectExists: []string{filepath.Join(root, "private", "key")},
+		},
+		{
+			name:      "RemoveAll of a root",
+			modify:    func() error { return s.RemoveAll(root) },
+			expectErr: os.ErrPermission,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.modify()
+
+			switch {
+			case tc.expectDenied:
+				if !errors.Is(err, ErrOutsideSandbox) {
+					t.Errorf("Expected ErrOutsideSandbox, but got %v", err)
+				}
+			case tc.expectErr != nil:
+				if !errors.Is(err, tc.expectErr) {
+					t.Errorf("Expected %v, but got %v", tc.expectErr, err)
+				}
+			default:
+				if err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+				}
+			}
+
+			for _, path := range tc.expectExists {
+				if _, err := os.Lstat(path); err != nil {
+					t.Errorf("Expected %v to exist, but got: %v", path, err)
+				}
+			}
+			for _, path := range tc.expectGone {
+				if _, err := os.Lstat(path); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("Expected %v not to exist, but got: %v", path, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+var ErrOutsideSandbox = errors.New("path is outside of the allowed roots")
+
+// Sandbox restricts file access to a set of root directories. paths are resolved by the kernel with
+// openat2(RESOLVE_BENEATH | RESOLVE_NO_MAGICLINKS) relative to the root they belong to, so neither ".."
+// nor symlinks can be used to escape a root, even if the tree changes while it is being resolved
+type Sandbox struct {
+	roots  []string
+	denied []string
+}
+
+func New(roots, denied []string) (*Sandbox, error) {
+	s := &Sandbox{}
+
+	for _, root := range roots {
+		if !filepath.IsAbs(root) {
+			return nil, fmt.Errorf("allowed root %v is not an absolute path", root)
+		}
+		s.roots = append(s.roots, filepath.Clean(root))
+	}
+
+	for _, path := range denied {
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("denied path %v is not an absolute path", path)
+		}
+		s.denied = append(s.denied, filepath.Clean(path))
+	}
+
+	return s, nil
+}
+
+func (s *Sandbox) Roots() []string {
+	return s.roots
+}
+
+// Resolve lexically checks that path belongs to one of the allowed roots and returns it cleaned.
+// it does not touch the file system, use Open or Stat before trusting the result
+func (s *Sandbox) Resolve(path string) (string, error) {
+	_, cleaned, err := s.split(path)
+	return cleaned, err
+}
+
+func (s *Sandbox) Open(path string) (*os.File, error) {
+	return s.OpenFile(path, os.O_RDONLY, 0)
+}
+
+func (s *Sandbox) OpenFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	root, cleaned, err := s.split(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := filepath.Rel(root, cleaned)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: err}
+	}
+
+	rootFd, err := unix.Open(root, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: root, Err: err}
+	}
+	defer unix.Close(rootFd)
+
+	fd, err := openBeneath(rootFd, rel, flag, perm, unix.RESOLVE_BENEATH|unix.RESOLVE_NO_MAGICLINKS)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: err}
+	}
+
+	file := os.NewFile(uintptr(fd), cleaned)
+
+	// symlinks inside a root are allowed, so the denied list has to be checked against where we actually ended up.
+	// if that can't be determined the file is refused rather than let through unchecked
+	realPath, err := fdPath(fd)
+	if err != nil {
+		file.Close()
+		return nil, &fs.PathError{Op: "open", Path: path, Err: err}
+	}
+	if s.isDenied(realPath) {
+		file.Close()
+		return nil, &fs.PathError{Op: "open", Path: path, Err: ErrOutsideSandbox}
+	}
+
+	return file, nil
+}
+
+func (s *Sandbox) Stat(path string) (os.FileInfo, error) {
+	file, err := s.OpenFile(path, unix.O_PATH, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return file.Stat()
+}
+
+// RealPath returns path with every symlink resolved, so paths reached through different links can be compared.
+// the elements at the end of path that don't exist yet are kept as they are
+func (s *Sandbox) RealPath(path string) (string, error) {
+	_, cleaned, err := s.split(path)
+	if err != nil {
+		return "", err
+	}
+
+	missing := ""
+	for {
+		file, err := s.OpenFile(cleaned, unix.O_PATH, 0)
+		if errors.Is(err, fs.ErrNotExist) {
+			missing = filepath.Join(filepath.Base(cleaned), missing)
+			cleaned = filepath.Dir(cleaned)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		resolved, err := fdPath(int(file.Fd()))
+		file.Close()
+		if err != nil {
+			return "", &fs.PathError{Op: "resolve", Path: path, Err: err}
+		}
+		return filepath.Join(resolved, missing), nil
+	}
+}
+
+// WalkFiles calls fn for every regular file below path with its path relative to path. every entry is opened
+// relative to its parent directory without following symlinks, so entries swapped out mid-walk cannot escape.
+// entries that can't be read are skipped. the walk stops with ctx.Err() as soon as ctx is cancelled
+func (s *Sandbox) WalkFiles(ctx context.Context, path string, fn func(rel string, file *os.File, info fs.FileInfo) error) error {
+	return s.walkFrom(ctx, path, walkOptions{open: true}, fn)
+}
+
+// Walk is WalkFiles that also calls fn for every folder below path, before the entries in it
+func (s *Sandbox) Walk(ctx context.Context, path string, fn func(rel string, file *os.File, info fs.FileInfo) error) error {
+	return s.walkFrom(ctx, path, walkOptions{open: true, dirs: true}, fn)
+}
+
+// StatFiles is WalkFiles for callers that only need the file info. files are stat'ed relative to their parent
+// directory instead of being opened, so files without read permission are still visited
+func (s *Sandbox) StatFiles(ctx context.Context, path string, fn func(rel string, info fs.FileInfo) error) error {
+	return s.walkFrom(ctx, path, walkOptions{}, func(rel string, _ *os.File, info fs.FileInfo) error {
+		return fn(rel, info)
+	})
+}
+
+// walkOptions decide whether files are opened or only stat'ed and whether fn is called for folders as well
+type walkOptions struct {
+	open bool
+	dirs bool
+}
+
+func (s *Sandbox) walkFrom(ctx context.Context, path string, opts walkOptions, fn func(rel string, file *os.File, info fs.FileInfo) error) error {
+	dir, err := s.OpenFile(path, os.O_RDONLY|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	base, err := fdPath(int(dir.Fd()))
+	if err != nil {
+		return err
+	}
+
+	return s.walk(ctx, dir, base, "", opts, fn)
+}
+
+func (s *Sandbox) walk(ctx context.Context, dir *os.File, base, rel string, opts walkOptions, fn func(rel string, file *os.File, info fs.FileInfo) error) error {
+	entries, err := dir.ReadDir(-1)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entryRel := filepath.Join(rel, entry.Name())
+		entryPath := filepath.Join(base, entryRel)
+		if s.isDenied(entryPath) {
+			continue
+		}
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			continue
+		}
+
+		if !opts.open && entry.Type().IsRegular() {
+			info, err := statAt(int(dir.Fd()), entry.Name())
+			if err != nil {
+				if skipEntry(ctx, entryPath, err) {
+					continue
+				}
+				return &fs.PathError{Op: "stat", Path: entryPath, Err: err}
+			}
+			// only regular files are visited, the entry may have been replaced since we read the directory
+			if !info.Mode().IsRegular() {
+				continue
+			}
+
+			err = fn(entryRel, nil, info)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		fd, err := openBeneath(int(dir.Fd()), entry.Name(), os.O_RDONLY|unix.O_NOFOLLOW, 0, unix.RESOLVE_BENEATH|unix.RESOLVE_NO_SYMLINKS|unix.RESOLVE_NO_MAGICLINKS)
+		if err != nil {
+			if skipEntry(ctx, entryPath, err) {
+				continue
+			}
+			return &fs.PathError{Op: "open", Path: entryPath, Err: err}
+		}
+		file := os.NewFile(uintptr(fd), entryPath)
+
+		err = s.visit(ctx, file, base, entryRel, opts, fn)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Sandbox) visit(ctx context.Context, file *os.File, base, rel string, opts walkOptions, fn func(rel string, file *os.File, info fs.FileInfo) error) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		if opts.dirs {
+			err = fn(rel, file, info)
+			if err != nil {
+				return err
+			}
+		}
+		return s.walk(ctx, file, base, rel, opts, fn)
+	case info.Mode().IsRegular():
+		return fn(rel, file, info)
+	}
+
+	return nil
+}
+
+// skipEntry reports whether a walk should carry on without the entry at path after failing to open or stat it
+func skipEntry(ctx context.Context, path string, err error) bool {
+	switch {
+	case errors.Is(err, unix.ENOENT), errors.Is(err, unix.ELOOP):
+		// removed or replaced by a symlink since we read the directory
+		return true
+	case errors.Is(err, unix.EACCES), errors.Is(err, unix.EPERM):
+		slog.WarnContext(ctx, "skipping unreadable entry", "path", path, "error", err)
+		return true
+	}
+	return false
+}
+
+func (s *Sandbox) split(path string) (root string, cleaned string, err error) {
+	if !filepath.IsAbs(path) {
+		return "", "", &fs.PathError{Op: "resolve", Path: path, Err: fmt.Errorf("path must be absolute")}
+	}
+	cleaned = filepath.Clean(path)
+
+	if s.isDenied(cleaned) {
+		return "", "", &fs.PathError{Op: "resolve", Path: path, Err: ErrOutsideSandbox}
+	}
+
+	for _, r := range s.roots {
+		if isWithin(r, cleaned) && len(r) > len(root) {
+			root = r
+		}
+	}
+	if root == "" {
+		return "", "", &fs.PathError{Op: "resolve", Path: path, Err: ErrOutsideSandbox}
+	}
+
+	return root, cleaned, nil
+}
+
+// fdPath returns the path the descriptor currently refers to
+func fdPath(fd int) (string, error) {
+	path, err := os.Readlink(fmt.Sprintf("/proc/self/fd/%v", fd))
+	if err != nil {
+		return "", fmt.Errorf("could not resolve the opened path: %w", err)
+	}
+	return path, nil
+}
+
+func (s *Sandbox) isDenied(path string) bool {
+	for _, denied := range s.denied {
+		if isWithin(denied, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWithin(root, path string) bool {
+	return path == root || root == "/" || strings.HasPrefix(path, root+"/")
+}
+
+func openBeneath(dirFd int, path string, flag int, perm os.FileMode, resolve uint64) (int, error) {
+	how := unix.OpenHow{
+		Flags:   uint64(flag | unix.O_CLOEXEC),
+		Resolve: resolve,
+	}
+	if flag&os.O_CREATE != 0 {
+		how.Mode = uint64(perm.Perm())
+	}
+
+	for {
+		fd, err := unix.Openat2(dirFd, path, &how)
+		if errors.Is(err, unix.EINTR) || errors.Is(err, unix.EAGAIN) {
+			continue
+		}
+		if errors.Is(err, unix.EXDEV) {
+			return -1, ErrOutsideSandbox
+		}
+		return fd, err
+	}
+}
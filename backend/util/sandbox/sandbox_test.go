@@ -0,0 +1,287 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// Helper function to build a root with a secret file next to it
+func setupSandbox(t *testing.T) (*Sandbox, string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	outside := filepath.Join(dir, "outside")
+
+	for _, d := range []string{root, filepath.Join(root, "docs"), filepath.Join(root, "private"), outside} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{filepath.Join(root, "docs", "a.txt"), filepath.Join(root, "private", "key"), filepath.Join(outside, "secret")} {
+		if err := os.WriteFile(f, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("docs", filepath.Join(root, "docs-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New([]string{root}, []string{filepath.Join(root, "private")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, root, outside
+}
+
+func TestOpen(t *testing.T) {
+	s, root, outside := setupSandbox(t)
+
+	testCases := []struct {
+		name          string
+		path          string
+		expectDenied  bool
+		expectAnyFail bool
+	}{
+		{name: "File inside root", path: filepath.Join(root, "docs", "a.txt")},
+		{name: "Symlink inside root", path: filepath.Join(root, "docs-link", "a.txt")},
+		{name: "Root itself", path: root},
+		{name: "Dot dot escape", path: root + "/docs/../../outside/secret", expectDenied: true},
+		{name: "Path outside root", path: filepath.Join(outside, "secret"), expectDenied: true},
+		{name: "Symlink escape", path: filepath.Join(root, "escape", "secret"), expectDenied: true},
+		{name: "Denied path", path: filepath.Join(root, "private", "key"), expectDenied: true},
+		{name: "Relative path", path: "docs/a.txt", expectAnyFail: true},
+		{name: "Missing file", path: filepath.Join(root, "missing"), expectAnyFail: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := s.Open(tc.path)
+			if err == nil {
+				file.Close()
+			}
+
+			switch {
+			case tc.expectDenied:
+				if !errors.Is(err, ErrOutsideSandbox) {
+					t.Errorf("Expected ErrOutsideSandbox, but got %v", err)
+				}
+			case tc.expectAnyFail:
+				if err == nil {
+					t.Errorf("Expected an error, but got nil")
+				}
+			default:
+				if err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestWalkFiles(t *testing.T) {
+	s, root, _ := setupSandbox(t)
+
+	files := []string{}
+	err := s.WalkFiles(context.Background(), root, func(rel string, file *os.File, info fs.FileInfo) error {
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// symlinks are skipped and the denied folder is never entered
+	want := []string{filepath.Join("docs", "a.txt")}
+	if !slices.Equal(files, want) {
+		t.Errorf("Files mismatch: got %v, want %v", files, want)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	s, root, _ := setupSandbox(t)
+
+	if err := os.MkdirAll(filepath.Join(root, "docs", "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	visited := []string{}
+	err := s.Walk(context.Background(), root, func(rel string, file *os.File, info fs.FileInfo) error {
+		if info.IsDir() {
+			rel += "/"
+		}
+		visited = append(visited, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// folders come before their entries, empty ones included
+	if len(visited) == 0 || visited[0] != "docs/" {
+		t.Errorf("Expected docs/ to be visited first, but got %v", visited)
+	}
+	slices.Sort(visited)
+	want := []string{"docs/", filepath.Join("docs", "a.txt"), filepath.Join("docs", "empty") + "/"}
+	if !slices.Equal(visited, want) {
+		t.Errorf("Entries mismatch: got %v, want %v", visited, want)
+	}
+}
+
+func TestWalkFilesCancelled(t *testing.T) {
+	s, root, _ := setupSandbox(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.WalkFiles(ctx, root, func(rel string, file *os.File, info fs.FileInfo) error {
+		t.Errorf("Expected the walk to stop before visiting %v", rel)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got: %v", err)
+	}
+}
+
+func TestStatFiles(t *testing.T) {
+	s, root, _ := setupSandbox(t)
+
+	// stat'ing does not need read permission
+	if err := os.WriteFile(filepath.Join(root, "docs", "locked"), []byte("locked data"), 0o000); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []string{}
+	err := s.StatFiles(context.Background(), root, func(rel string, info fs.FileInfo) error {
+		files = append(files, rel)
+
+		want, err := os.Lstat(filepath.Join(root, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Name() != want.Name() || info.Size() != want.Size() || info.Mode() != want.Mode() || !info.ModTime().Equal(want.ModTime()) {
+			t.Errorf("Info mismatch for %v: got %v %v %v %v, want %v %v %v %v", rel, info.Name(), info.Size(), info.Mode(), info.ModTime(), want.Name(), want.Size(), want.Mode(), want.ModTime())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// directory order is up to the file system
+	slices.Sort(files)
+	want := []string{filepath.Join("docs", "a.txt"), filepath.Join("docs", "locked")}
+	if !slices.Equal(files, want) {
+		t.Errorf("Files mismatch: got %v, want %v", files, want)
+	}
+}
+
+func TestWalkFilesSkipsUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	s, root, _ := setupSandbox(t)
+
+	locked := filepath.Join(root, "locked")
+	if err := os.Mkdir(locked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(locked, "b.txt"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "unreadable"), []byte("data"), 0o000); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0o755) })
+
+	files := []string{}
+	err := s.WalkFiles(context.Background(), root, func(rel string, file *os.File, info fs.FileInfo) error {
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	want := []string{filepath.Join("docs", "a.txt")}
+	if !slices.Equal(files, want) {
+		t.Errorf("Files mismatch: got %v, want %v", files, want)
+	}
+}
+
+func TestSkipEntry(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		expectSkip bool
+	}{
+		{name: "Removed", err: unix.ENOENT, expectSkip: true},
+		{name: "Replaced by a symlink", err: unix.ELOOP, expectSkip: true},
+		{name: "Permission denied", err: unix.EACCES, expectSkip: true},
+		{name: "Operation not permitted", err: unix.EPERM, expectSkip: true},
+		{name: "IO error", err: unix.EIO},
+		{name: "Escape", err: ErrOutsideSandbox},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			skip := skipEntry(context.Background(), "/root/entry", tc.err)
+			if skip != tc.expectSkip {
+				t.Errorf("Expected skip %v, but got %v", tc.expectSkip, skip)
+			}
+		})
+	}
+}
+
+func TestRealPath(t *testing.T) {
+	s, root, _ := setupSandbox(t)
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name         string
+		path         string
+		expected     string
+		expectDenied bool
+	}{
+		{name: "Plain path", path: filepath.Join(root, "docs", "a.txt"), expected: filepath.Join(realRoot, "docs", "a.txt")},
+		{name: "Symlink", path: filepath.Join(root, "docs-link"), expected: filepath.Join(realRoot, "docs")},
+		{name: "Missing elements below a symlink", path: filepath.Join(root, "docs-link", "new", "b.txt"), expected: filepath.Join(realRoot, "docs", "new", "b.txt")},
+		{name: "Symlink escape", path: filepath.Join(root, "escape", "secret"), expectDenied: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := s.RealPath(tc.path)
+			if tc.expectDenied {
+				if !errors.Is(err, ErrOutsideSandbox) {
+					t.Errorf("Expected ErrOutsideSandbox, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if path != tc.expected {
+				t.Errorf("Expected %v, but got %v", tc.expected, path)
+			}
+		})
+	}
+}
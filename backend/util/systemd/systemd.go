@@ -0,0 +1,128 @@
+package systemd
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenFdsStart is the first file descriptor passed by socket activation, after stdin, stdout and stderr
+const listenFdsStart = 3
+
+// Listeners returns the sockets passed by systemd socket activation, or nothing when the process was not
+// socket activated. the environment variables are unset so child processes don't pick the sockets up
+func Listeners() ([]net.Listener, error) {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := []net.Listener{}
+	for i := 0; i < count; i++ {
+		fd := listenFdsStart + i
+		unix.CloseOnExec(fd)
+
+		name := fmt.Sprintf("LISTEN_FD_%v", fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(file)
+		// FileListener dups the descriptor
+		file.Close()
+		if err != nil {
+			for _, open := range listeners {
+				open.Close()
+			}
+			return nil, fmt.Errorf("socket %v passed by systemd is not a listening socket: %w", name, err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	return listeners, nil
+}
+
+// Notify sends a state like "READY=1" to the service manager. it does nothing when the process was not started
+// by systemd with a notify socket
+func Notify(state string) error {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return nil
+	}
+	if strings.HasPrefix(path, "@") {
+		// abstract namespace
+		path = "\x00" + path[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// Reloading tells the service manager that the config is being reloaded. send "READY=1" once done
+func Reloading() error {
+	var now unix.Timespec
+	err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &now)
+	if err != nil {
+		return err
+	}
+	return Notify(fmt.Sprintf("RELOADING=1\nMONOTONIC_USEC=%v", now.Nano()/1000))
+}
+
+// WatchdogInterval returns how often the service manager expects "WATCHDOG=1", or false when the watchdog is
+// not enabled for this process
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// StartWatchdog pings the watchdog at half its interval until the process exits
+func StartWatchdog() {
+	interval, ok := WatchdogInterval()
+	if !ok {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			err := Notify("WATCHDOG=1")
+			if err != nil {
+				slog.Warn("could not ping the systemd watchdog", "error", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,98 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	err = Notify("READY=1")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Expected a message, but got: %v", err)
+	}
+	if string(buf[:n]) != "READY=1" {
+		t.Errorf("Message mismatch: got %q, want %q", buf[:n], "READY=1")
+	}
+
+	t.Setenv("NOTIFY_SOCKET", "")
+	err = Notify("READY=1")
+	if err != nil {
+		t.Errorf("Expected no error without a notify socket, but got: %v", err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+
+	testCases := []struct {
+		name         string
+		usec         string
+		pid          string
+		wantInterval time.Duration
+		wantOk       bool
+	}{
+		{name: "Not Enabled", usec: ""},
+		{name: "Enabled", usec: "30000000", wantInterval: 30 * time.Second, wantOk: true},
+		{name: "Enabled for This Process", usec: "1000", pid: pid, wantInterval: time.Millisecond, wantOk: true},
+		{name: "Enabled for Another Process", usec: "1000", pid: "1"},
+		{name: "Invalid", usec: "soon"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tc.usec)
+			t.Setenv("WATCHDOG_PID", tc.pid)
+
+			interval, ok := WatchdogInterval()
+			if interval != tc.wantInterval || ok != tc.wantOk {
+				t.Errorf("Got (%v, %v), want (%v, %v)", interval, ok, tc.wantInterval, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestListeners(t *testing.T) {
+	testCases := []struct {
+		name string
+		pid  string
+		fds  string
+	}{
+		{name: "Not Activated", pid: "", fds: ""},
+		{name: "Activated for Another Process", pid: "1", fds: "1"},
+		{name: "No Sockets", pid: strconv.Itoa(os.Getpid()), fds: "0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("LISTEN_PID", tc.pid)
+			t.Setenv("LISTEN_FDS", tc.fds)
+
+			listeners, err := Listeners()
+			if err != nil || len(listeners) != 0 {
+				t.Errorf("Expected no listeners, but got %v, %v", listeners, err)
+			}
+			if _, ok := os.LookupEnv("LISTEN_FDS"); ok {
+				t.Errorf("Expected LISTEN_FDS to be unset")
+			}
+		})
+	}
+}